@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"github.com/ontypehq/vox/internal/config"
+	"github.com/ontypehq/vox/internal/dashscope"
 	"github.com/ontypehq/vox/internal/ui"
 )
 
@@ -54,10 +55,18 @@ type AuthStatusCmd struct{}
 func (c *AuthStatusCmd) Run(cfg *config.AppConfig) error {
 	any := false
 
-	if key := cfg.Config.Services.DashScope.APIKey; key != "" {
+	if ds := cfg.DashScope(); ds.APIKey != "" {
 		any = true
 		ui.Success("dashscope")
-		ui.KV("  API Key", maskToken(key))
+		ui.KV("  API Key", maskToken(ds.APIKey))
+		ep, err := dashscope.ResolveEndpoints(ds.Region, ds.BaseURL, ds.HTTPURL, ds.WSURL)
+		if err != nil {
+			ui.Warn("  %v", err)
+		} else {
+			ui.KV("  Region", ep.Region)
+			ui.KV("  HTTP", ep.HTTP)
+			ui.KV("  Realtime", ep.WS)
+		}
 	}
 
 	if s := cfg.Config.Services.Slack; s.BotToken != "" {
@@ -1,16 +1,25 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/ontypehq/vox/internal/cache"
 	"github.com/ontypehq/vox/internal/config"
 	"github.com/ontypehq/vox/internal/ui"
 )
 
 type CacheCmd struct {
 	Status CacheStatusCmd `cmd:"" default:"withargs" help:"Show cache size and file count"`
+	List   CacheListCmd   `cmd:"" help:"List cached speech and transcripts, most recently used first"`
+	Search CacheSearchCmd `cmd:"" help:"Find cache entries by text, voice or source file"`
+	Play   CachePlayCmd   `cmd:"" help:"Play cached speech"`
 	Clear  CacheClearCmd  `cmd:"" help:"Delete all cached audio"`
 }
 
@@ -25,18 +34,175 @@ func (c *CacheStatusCmd) Run(cfg *config.AppConfig) error {
 	}
 
 	var totalSize int64
+	var files int
 	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), cache.IndexFile) {
+			continue
+		}
+		files++
 		if info, err := e.Info(); err == nil {
 			totalSize += info.Size()
 		}
 	}
 
 	ui.KV("Path", dir)
-	ui.KV("Files", fmt.Sprintf("%d", len(entries)))
+	ui.KV("Files", fmt.Sprintf("%d", files))
 	ui.KV("Size", formatSize(totalSize))
 	return nil
 }
 
+type CacheListCmd struct {
+	Limit int  `short:"n" default:"20" help:"Show at most this many entries (0 = all)"`
+	JSON  bool `help:"Print entries as JSON to stdout"`
+}
+
+func (c *CacheListCmd) Run(cfg *config.AppConfig) error {
+	ix, unindexed, err := loadCacheIndex(cfg)
+	if err != nil {
+		return err
+	}
+	entries := ix.Recent()
+	if c.JSON {
+		return printCacheJSON(entries)
+	}
+	if len(entries) == 0 {
+		ui.Info("%s %s", ui.Dim("cache"), ui.Dim("empty"))
+	} else {
+		shown := entries
+		if c.Limit > 0 && len(shown) > c.Limit {
+			shown = shown[:c.Limit]
+		}
+		ui.Info("\n%s %s", ui.Key("Cache"), ui.Dim(fmt.Sprintf("(%d)", len(entries))))
+		ui.Info("%s", ui.Dim("  (replay with: vox cache play <id>)"))
+		printCacheEntries(shown)
+		if len(shown) < len(entries) {
+			ui.Info("%s", ui.Dim(fmt.Sprintf("  ... %d more (use -n 0 to show all)", len(entries)-len(shown))))
+		}
+	}
+	if unindexed > 0 {
+		ui.Info("%s", ui.Dim(fmt.Sprintf("  %d older files are not indexed", unindexed)))
+	}
+	return nil
+}
+
+type CacheSearchCmd struct {
+	Query string `arg:"" help:"Text to look for (case-insensitive)"`
+	JSON  bool   `help:"Print matching entries as JSON to stdout"`
+}
+
+func (c *CacheSearchCmd) Run(cfg *config.AppConfig) error {
+	ix, _, err := loadCacheIndex(cfg)
+	if err != nil {
+		return err
+	}
+	found := ix.Search(c.Query)
+	if c.JSON {
+		return printCacheJSON(found)
+	}
+	if len(found) == 0 {
+		ui.Info("%s", ui.Dim(fmt.Sprintf("no cache entries match %q", c.Query)))
+		return nil
+	}
+	ui.Info("\n%s %s", ui.Key("Matches"), ui.Dim(fmt.Sprintf("(%d)", len(found))))
+	printCacheEntries(found)
+	return nil
+}
+
+type CachePlayCmd struct {
+	ID string `arg:"" help:"Entry ID from vox cache list (or the start of one)"`
+}
+
+func (c *CachePlayCmd) Run(cfg *config.AppConfig) error {
+	dir := filepath.Join(cfg.Dir, "cache")
+	ix, err := cache.Load(dir)
+	if err != nil {
+		return fmt.Errorf("cache index: %w", err)
+	}
+	e, err := ix.Find(c.ID)
+	if err != nil {
+		return err
+	}
+	if e.Kind != cache.KindSpeech {
+		return fmt.Errorf("%s is a cached transcript, not audio", e.ID())
+	}
+	pcm, _, ok := readCachedAudio(cfg, e.Hash)
+	if !ok {
+		return fmt.Errorf("cached audio for %s is missing or unreadable", e.ID())
+	}
+	ui.Info("%s %s %s", ui.Dim("voice"), ui.Key(e.Voice), ui.Dim(excerpt(e.Text, 60)))
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	keys := bindPlaybackKeys(ctx, cancel, false, true)
+	defer keys.close()
+
+	sink, err := openSink(cfg)
+	if err != nil {
+		return err
+	}
+	keys.play(sink, nil)
+	showProgress(sink)
+	sink.Write(pcm)
+	sink.Close()
+	keys.done()
+	if ctx.Err() != nil {
+		ui.Info("%s", ui.Dim("stopped"))
+		return nil
+	}
+
+	cache.Touch(dir, e.File)
+	return nil
+}
+
+// loadCacheIndex reads the cache index, dropping entries whose files were
+// deleted, and counts the cache files it doesn't cover
+func loadCacheIndex(cfg *config.AppConfig) (*cache.Index, int, error) {
+	ix, err := cache.Load(filepath.Join(cfg.Dir, "cache"))
+	if err != nil {
+		return nil, 0, fmt.Errorf("cache index: %w", err)
+	}
+	unindexed, err := ix.Prune()
+	if err != nil {
+		ui.Warn("Couldn't update the cache index: %v", err)
+	}
+	return ix, unindexed, nil
+}
+
+// printCacheEntries lists entries one per line: ID, voice (or "transcript"),
+// length, when last used, and the start of the text
+func printCacheEntries(entries []cache.Entry) {
+	for _, e := range entries {
+		who := e.Voice
+		if e.Kind == cache.KindTranscript {
+			who = "transcript"
+		}
+		length := "-"
+		if e.Duration > 0 {
+			length = clock(time.Duration(e.Duration * float64(time.Second)))
+		}
+		used := e.LastUsed.Local().Format("2006-01-02 15:04")
+		ui.Info("  %s  %s %5s  %s  %s", ui.Key(e.ID()), ui.Dim(fmt.Sprintf("%-12s", who)), ui.Dim(length), ui.Dim(used), excerpt(e.Text, 60))
+	}
+}
+
+func printCacheJSON(entries []cache.Entry) error {
+	if entries == nil {
+		entries = []cache.Entry{}
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+// excerpt puts text on one line and cuts it to at most n characters
+func excerpt(text string, n int) string {
+	r := []rune(strings.Join(strings.Fields(text), " "))
+	if len(r) <= n {
+		return string(r)
+	}
+	return string(r[:n-1]) + "…"
+}
+
 type CacheClearCmd struct{}
 
 func (c *CacheClearCmd) Run(cfg *config.AppConfig) error {
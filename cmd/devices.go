@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ontypehq/vox/internal/audio"
+	"github.com/ontypehq/vox/internal/config"
+	"github.com/ontypehq/vox/internal/ui"
+)
+
+type DevicesCmd struct {
+	List DevicesListCmd `cmd:"" default:"withargs" help:"List input and output devices"`
+	Use  DevicesUseCmd  `cmd:"" help:"Save the default input and/or output device"`
+}
+
+// --- devices list ---
+
+type DevicesListCmd struct{}
+
+func (c *DevicesListCmd) Run(cfg *config.AppConfig) error {
+	inputs, err := audio.InputDevices()
+	if err != nil {
+		return err
+	}
+	outputs, err := audio.OutputDevices()
+	if err != nil {
+		return err
+	}
+
+	printDevices("Input Devices", inputs, cfg.InputDevice())
+	printDevices("Output Devices", outputs, cfg.OutputDevice())
+	ui.Info("\n%s", ui.Dim("  (use with: vox --input-device <name|number> hear, or save with: vox devices use --input <name>)"))
+	return nil
+}
+
+// printDevices lists devices, marking the system default and the one vox
+// is set to use
+func printDevices(title string, devices []audio.Device, selected string) {
+	ui.Info("\n%s", ui.Key(title))
+	if len(devices) == 0 {
+		ui.Info("%s", ui.Dim("  none found"))
+		return
+	}
+
+	chosen := -1
+	if selected != "" {
+		if d, err := audio.FindDevice(devices, selected); err == nil {
+			chosen = d.Index
+		} else {
+			ui.Warn("Configured device %q not found", selected)
+		}
+	}
+	for _, d := range devices {
+		var marks []string
+		if d.Default {
+			marks = append(marks, "default")
+		}
+		if d.Index == chosen {
+			marks = append(marks, "selected")
+		}
+		line := fmt.Sprintf("  %2d  %s", d.Index, ui.Key(d.Name))
+		if len(marks) > 0 {
+			line += "  " + ui.Dim("("+strings.Join(marks, ", ")+")")
+		}
+		ui.Info("%s", line)
+	}
+}
+
+// --- devices use ---
+
+type DevicesUseCmd struct {
+	Input  string `help:"Microphone to record from: name, number or ID ('default' for the system default)"`
+	Output string `help:"Device to play audio on: name, number or ID ('default' for the system default)"`
+}
+
+func (c *DevicesUseCmd) Run(cfg *config.AppConfig) error {
+	if c.Input == "" && c.Output == "" {
+		return fmt.Errorf("nothing to set — pass --input and/or --output")
+	}
+
+	if c.Input != "" {
+		name, err := resolveDevice(c.Input, audio.InputDevices)
+		if err != nil {
+			return fmt.Errorf("input: %w", err)
+		}
+		cfg.Config.Audio.InputDevice = name
+		ui.KV("Input", deviceLabel(name))
+	}
+	if c.Output != "" {
+		name, err := resolveDevice(c.Output, audio.OutputDevices)
+		if err != nil {
+			return fmt.Errorf("output: %w", err)
+		}
+		cfg.Config.Audio.OutputDevice = name
+		ui.KV("Output", deviceLabel(name))
+	}
+
+	if err := cfg.SaveConfig(); err != nil {
+		return fmt.Errorf("save config: %w", err)
+	}
+	ui.Success("Saved audio devices")
+	return nil
+}
+
+// resolveDevice turns a query into the device name saved in config. Names
+// are kept rather than numbers or IDs, which change as devices come and go.
+func resolveDevice(query string, list func() ([]audio.Device, error)) (string, error) {
+	if strings.EqualFold(query, "default") {
+		return "", nil
+	}
+	devices, err := list()
+	if err != nil {
+		return "", err
+	}
+	d, err := audio.FindDevice(devices, query)
+	if err != nil {
+		return "", err
+	}
+	return d.Name, nil
+}
+
+func deviceLabel(name string) string {
+	if name == "" {
+		return "system default"
+	}
+	return name
+}
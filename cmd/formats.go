@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ontypehq/vox/internal/audio"
+	"github.com/ontypehq/vox/internal/ui"
+)
+
+type FormatsCmd struct{}
+
+func (c *FormatsCmd) Run() error {
+	ui.Info("\n%s", ui.Key("Output Formats"))
+	ui.Info("%s", ui.Dim("  (use with: vox say -o file.<ext> or --format <name>)"))
+	for _, name := range audio.FormatNames() {
+		f, _ := audio.LookupFormat(name)
+		exts := strings.Join(f.Extensions, " ")
+		if exts == "" {
+			exts = "--format only"
+		}
+		line := fmt.Sprintf("  %s %s  %s", ui.Key(fmt.Sprintf("%-9s", f.Name)), ui.Dim(fmt.Sprintf("%-20s", exts)), f.Help)
+		if err := f.Available(); err != nil {
+			line += "  " + ui.Dim("("+f.Tool+" not found)")
+		}
+		ui.Info("%s", line)
+	}
+	return nil
+}
@@ -1,84 +1,190 @@
 package cmd
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/ontypehq/vox/internal/audio"
+	"github.com/ontypehq/vox/internal/audio/wav"
+	"github.com/ontypehq/vox/internal/cache"
 	"github.com/ontypehq/vox/internal/config"
-	"github.com/ontypehq/vox/internal/dashscope"
+	"github.com/ontypehq/vox/internal/provider"
+	"github.com/ontypehq/vox/internal/subtitle"
 	"github.com/ontypehq/vox/internal/ui"
 )
 
 const asrSampleRate = 16000
 
+const (
+	// liveChunk is the audio sent per message in live mode (100ms)
+	liveChunk = asrSampleRate * 2 / 10
+	// liveBuffer is how far live transcription may fall behind the
+	// microphone before the oldest audio is dropped
+	liveBuffer = 30 * time.Second
+	// batchBuffer is the longest recording kept for --batch
+	batchBuffer = 30 * time.Minute
+	// finalTimeout is how long live mode waits for the final transcript
+	// after recording stops
+	finalTimeout = 30 * time.Second
+)
+
+const (
+	// captionPause is the shortest silence that starts a new caption
+	captionPause = 400 * time.Millisecond
+	// captionMaxLen keeps a caption short enough to read on screen
+	captionMaxLen = 8 * time.Second
+)
+
 type HearCmd struct {
-	File     string `short:"f" help:"Transcribe an existing audio file instead of recording"`
-	Duration int    `short:"d" default:"5" help:"Recording duration in seconds"`
+	File     string `short:"f" help:"Transcribe an existing audio file instead of recording ('-' reads stdin)"`
+	Duration int    `short:"d" default:"5" help:"Recording duration in seconds (0 = until Ctrl+C)"`
 	Context  string `short:"c" help:"Text context to improve recognition (e.g. domain terms)"`
+	Batch    bool   `help:"Record first, then transcribe in one request (no live transcript)"`
+	Parallel int    `default:"4" help:"Chunks of long files transcribed concurrently"`
+	Format   string `default:"txt" enum:"txt,srt,vtt,json" help:"Output format: txt, srt, vtt or json (timed formats imply --batch)"`
 	NoCache  bool   `help:"Skip transcription cache"`
+	Clean    bool   `help:"Clean up the audio before transcribing: high-pass, noise reduction, silence trimming, normalization (implies --batch; -f needs WAV)"`
+
+	VAD          bool          `help:"Start on speech and stop after a pause (-d becomes how long to wait for speech)"`
+	VADThreshold float64       `name:"vad-threshold" placeholder:"DB" help:"Speech level in dBFS, e.g. -40 (0 = adapt to background noise)"`
+	VADHangover  time.Duration `name:"vad-hangover" default:"1s" help:"Silence that ends the recording with --vad"`
+	VADMax       time.Duration `name:"vad-max" default:"2m" help:"Longest recording with --vad"`
+
+	inputDevice string
+}
+
+func (c *HearCmd) vadConfig() audio.VADConfig {
+	return audio.VADConfig{Threshold: c.VADThreshold, Hangover: c.VADHangover, MaxLength: c.VADMax}
+}
+
+// timed reports whether the output format needs segment timestamps
+func (c *HearCmd) timed() bool {
+	return c.Format != "txt"
 }
 
 func (c *HearCmd) Run(cfg *config.AppConfig) error {
-	apiKey, err := cfg.RequireAPIKey()
+	c.inputDevice = cfg.InputDevice()
+	p, err := provider.Open(cfg)
 	if err != nil {
 		return err
 	}
+	defer p.Close()
+
+	if c.File == "" && !c.Batch && !c.Clean && !c.timed() {
+		if st, ok := p.(provider.StreamingTranscriber); ok {
+			return c.runLive(st)
+		}
+	}
 
 	var wavData []byte
 	var cacheKey string
 
 	if c.File != "" {
-		wavData, err = os.ReadFile(c.File)
-		if err != nil {
-			return fmt.Errorf("read file: %w", err)
+		if c.File == "-" {
+			wavData, err = io.ReadAll(os.Stdin)
+			if err != nil {
+				return fmt.Errorf("read stdin: %w", err)
+			}
+			ui.Info("%s %s", ui.Dim("file"), ui.Key("stdin"))
+		} else {
+			wavData, err = os.ReadFile(c.File)
+			if err != nil {
+				return fmt.Errorf("read file: %w", err)
+			}
+			ui.Info("%s %s", ui.Dim("file"), ui.Key(c.File))
+		}
+		if len(wavData) == 0 {
+			return fmt.Errorf("no audio to transcribe")
 		}
-		ui.Info("%s %s", ui.Dim("file"), ui.Key(c.File))
 
 		// Cache key = hash of file content + context
 		h := sha256.New()
 		h.Write(wavData)
 		h.Write([]byte(":" + c.Context))
+		if c.Clean {
+			h.Write([]byte(":clean"))
+		}
 		cacheKey = hex.EncodeToString(h.Sum(nil))
 
 		// Check cache
 		if !c.NoCache {
-			cachePath := filepath.Join(cfg.Dir, "cache", "asr-"+cacheKey+".txt")
-			if cached, err := os.ReadFile(cachePath); err == nil {
+			if cached, ok := c.readCache(cfg, cacheKey); ok {
 				ui.Info("%s", ui.Dim("cached"))
-				fmt.Println(string(cached))
-				return nil
+				cache.Touch(filepath.Join(cfg.Dir, "cache"), filepath.Base(c.cachePath(cfg, cacheKey)))
+				return c.print(cached)
+			}
+		}
+		if c.Clean {
+			if wavData, err = cleanFile(wavData); err != nil {
+				return err
 			}
 		}
 	} else {
-		ui.Info("Recording for %ds... %s", c.Duration, ui.Dim("(speak now)"))
-
-		recorder, err := audio.NewRecorder(asrSampleRate, 1)
+		recorder, err := audio.NewRecorder(asrSampleRate, 1, c.inputDevice)
 		if err != nil {
 			return fmt.Errorf("init recorder: %w", err)
 		}
 
+		var vad *audio.VAD
+		if c.VAD {
+			vad = audio.NewVAD(asrSampleRate, c.vadConfig())
+			recorder.OnData(vad.Write)
+			ui.Info("%s %s", vadBanner(c.Duration), ui.Dim("(speak now)"))
+		} else {
+			ui.Info("%s %s", recordingBanner(c.Duration), ui.Dim("(speak now)"))
+		}
+
+		// The VAD limit is a wait for speech, not a recording length
+		limit := time.Duration(c.Duration) * time.Second
+		if vad != nil {
+			limit = 0
+		}
+		meter := meterRecorder(recorder, limit)
+
+		// Past the bound, keep the start of the dictation and lose the end
+		recorder.Buffer(batchBuffer, audio.DropNewest)
+
 		if err := recorder.Start(); err != nil {
 			return fmt.Errorf("start recording: %w", err)
 		}
-		time.Sleep(time.Duration(c.Duration) * time.Second)
+		stop, cancel := c.stopContext(vad)
+		<-stop.Done()
+		cancel()
 		pcm := recorder.Stop()
+		meter.finish()
+		if lost := recorder.Dropped(); lost > 0 {
+			ui.Warn("Recording ran past %s; the last %s was dropped", batchBuffer, lost.Round(time.Second))
+		}
 
 		ui.Info("%s %s", ui.Dim("recorded"), ui.Dim(fmt.Sprintf("%d bytes", len(pcm))))
+		if c.Clean {
+			pcm = cleanRecording(pcm, asrSampleRate)
+		}
+		if vad != nil {
+			if pcm = audio.TrimSilence(pcm, asrSampleRate, c.vadConfig()); pcm == nil {
+				return fmt.Errorf("no speech detected")
+			}
+		}
 
-		wavData = wrapPCMAsWAVWithRate(pcm, asrSampleRate)
+		wavData = wav.Encode(pcm, asrSampleRate)
 	}
 
 	// Transcribe
 	t0 := time.Now()
-	ui.Info("%s %s", ui.Dim("model"), ui.Key(dashscope.ModelASRFlash))
+	ui.Info("%s %s", ui.Dim("model"), ui.Key(p.ASRModel()))
 
-	client := dashscope.NewClient(apiKey)
-	result, err := client.Transcribe(wavData, c.Context)
+	result, err := c.transcribe(p, wavData)
 	if err != nil {
 		return fmt.Errorf("transcribe: %w", err)
 	}
@@ -88,38 +194,429 @@ func (c *HearCmd) Run(cfg *config.AppConfig) error {
 
 	// Cache the result for file-based transcription
 	if cacheKey != "" && !c.NoCache && result.Text != "" {
-		cachePath := filepath.Join(cfg.Dir, "cache", "asr-"+cacheKey+".txt")
-		os.WriteFile(cachePath, []byte(result.Text), 0644)
+		c.writeCache(cfg, cacheKey, result, wavData)
 	}
 
 	// Output transcription to stdout (so it can be piped)
-	fmt.Println(result.Text)
+	return c.print(result)
+}
+
+// cleanFile runs a WAV file through the audio.Clean chain, resampled for ASR
+func cleanFile(data []byte) ([]byte, error) {
+	data, err := prepareUpload(data, asrSampleRate)
+	if err != nil {
+		return nil, err
+	}
+	a, err := wav.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("--clean needs WAV input: %w", err)
+	}
+	rate := a.Format.SampleRate
+	return wav.Encode(cleanRecording(a.Mono16(), rate), rate), nil
+}
+
+// transcribe sends short audio in one request. Long WAV files are split at
+// pauses and transcribed in parallel chunks. Timed formats go through
+// transcribeTimed.
+func (c *HearCmd) transcribe(p provider.Transcriber, wavData []byte) (*provider.Transcript, error) {
+	wavData, err := prepareUpload(wavData, asrSampleRate)
+	if err != nil {
+		return nil, err
+	}
+	a, err := wav.Decode(wavData)
+	if err != nil {
+		if c.timed() {
+			return nil, fmt.Errorf("--format %s needs WAV input: %w", c.Format, err)
+		}
+		// Not a WAV we can split (or another format): upload as-is
+		return p.Transcribe(wavData, c.Context)
+	}
+	if c.timed() {
+		return c.transcribeTimed(p, a)
+	}
+
+	rate := a.Format.SampleRate
+	pcm := a.Mono16()
+	limit := chunkLimit(rate)
+	if time.Duration(len(pcm)/2)*time.Second/time.Duration(rate) <= limit {
+		return p.Transcribe(wavData, c.Context)
+	}
+
+	chunks := audio.SplitAtSilence(pcm, rate, limit)
+	ui.Info("%s %s", ui.Dim("chunks"), ui.Dim(fmt.Sprintf("%d (split at pauses)", len(chunks))))
+	result, err := transcribeChunks(p.Transcribe, chunks, rate, c.Context, c.Parallel, false, func(done, total int) {
+		ui.Status("transcribed %d/%d chunks", done, total)
+	})
+	ui.ClearStatus()
+	return result, err
+}
+
+// transcribeTimed transcribes a recording with segment timing. Engines that
+// time their own transcripts get it in the usual long chunks. Otherwise (or
+// if they return no timing after all) the recording is split into
+// utterances at pauses and each one is transcribed on its own, its position
+// becoming the cue timing. That is one request per utterance, several
+// hundred for an hour of speech, sent --parallel at a time; an utterance
+// that fails is left out with a warning instead of failing the whole file.
+func (c *HearCmd) transcribeTimed(p provider.Transcriber, a *wav.Audio) (*provider.Transcript, error) {
+	rate := a.Format.SampleRate
+	pcm := a.Mono16()
+
+	var result *provider.Transcript
+	if tp, ok := p.(provider.TimedTranscriber); ok {
+		var timed atomic.Bool
+		transcribe := func(wavData []byte, hint string) (*provider.Transcript, error) {
+			t, err := tp.TranscribeTimed(wavData, hint)
+			if err == nil && len(t.Segments) > 0 {
+				timed.Store(true)
+			}
+			return t, err
+		}
+		chunks := audio.SplitAtSilence(pcm, rate, chunkLimit(rate))
+		var err error
+		result, err = transcribeChunks(transcribe, chunks, rate, c.Context, c.Parallel, false, func(done, total int) {
+			ui.Status("transcribed %d/%d chunks", done, total)
+		})
+		ui.ClearStatus()
+		if err != nil {
+			return nil, err
+		}
+		if !timed.Load() {
+			ui.Info("%s", ui.Dim("no timing returned, splitting into utterances"))
+			result = nil
+		}
+	}
+
+	if result == nil {
+		maxLen := min(captionMaxLen, chunkLimit(rate))
+		chunks := audio.SplitUtterances(pcm, rate, captionPause, maxLen)
+		if len(chunks) == 0 {
+			return &provider.Transcript{}, nil
+		}
+
+		ui.Info("%s %s", ui.Dim("utterances"), ui.Dim(fmt.Sprintf("%d (one request each)", len(chunks))))
+		var err error
+		result, err = transcribeChunks(p.Transcribe, chunks, rate, c.Context, c.Parallel, true, func(done, total int) {
+			ui.Status("transcribed %d/%d utterances", done, total)
+		})
+		ui.ClearStatus()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Drop utterances that turned out to be noise
+	segments := result.Segments[:0]
+	for _, seg := range result.Segments {
+		if seg.Text = strings.TrimSpace(seg.Text); seg.Text != "" {
+			segments = append(segments, seg)
+		}
+	}
+	result.Segments = segments
+	return result, nil
+}
+
+// transcriptJSON is the --format json output, and the cache format for
+// timed transcripts. Times are in seconds.
+type transcriptJSON struct {
+	Text     string        `json:"text"`
+	Segments []segmentJSON `json:"segments"`
+}
+
+type segmentJSON struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Text  string  `json:"text"`
+}
+
+func toJSON(t *provider.Transcript) transcriptJSON {
+	out := transcriptJSON{Text: t.Text, Segments: []segmentJSON{}}
+	for _, seg := range t.Segments {
+		out.Segments = append(out.Segments, segmentJSON{
+			Start: seg.Start.Round(time.Millisecond).Seconds(),
+			End:   seg.End.Round(time.Millisecond).Seconds(),
+			Text:  seg.Text,
+		})
+	}
+	return out
+}
+
+func fromJSON(t transcriptJSON) *provider.Transcript {
+	out := &provider.Transcript{Text: t.Text}
+	for _, seg := range t.Segments {
+		out.Segments = append(out.Segments, provider.TranscriptSegment{
+			Start: time.Duration(seg.Start * float64(time.Second)).Round(time.Millisecond),
+			End:   time.Duration(seg.End * float64(time.Second)).Round(time.Millisecond),
+			Text:  seg.Text,
+		})
+	}
+	return out
+}
+
+// print writes the transcript to stdout in the requested format
+func (c *HearCmd) print(t *provider.Transcript) error {
+	cues := make([]subtitle.Cue, len(t.Segments))
+	for i, seg := range t.Segments {
+		cues[i] = subtitle.Cue{Start: seg.Start, End: seg.End, Text: seg.Text}
+	}
+
+	switch c.Format {
+	case "srt":
+		return subtitle.WriteSRT(os.Stdout, cues)
+	case "vtt":
+		return subtitle.WriteVTT(os.Stdout, cues)
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(toJSON(t))
+	default:
+		fmt.Println(t.Text)
+		return nil
+	}
+}
+
+// cachePath returns where a file's transcript is cached. Timed formats
+// share one JSON entry with segments; plain text keeps its own.
+func (c *HearCmd) cachePath(cfg *config.AppConfig, key string) string {
+	if c.timed() {
+		return filepath.Join(cfg.Dir, "cache", "asr-"+key+".json")
+	}
+	return filepath.Join(cfg.Dir, "cache", "asr-"+key+".txt")
+}
+
+func (c *HearCmd) readCache(cfg *config.AppConfig, key string) (*provider.Transcript, bool) {
+	data, err := os.ReadFile(c.cachePath(cfg, key))
+	if err != nil {
+		return nil, false
+	}
+	if !c.timed() {
+		return &provider.Transcript{Text: string(data)}, true
+	}
+	var t transcriptJSON
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, false
+	}
+	return fromJSON(t), true
+}
+
+// writeCache stores a file's transcript and indexes it, with the length of
+// the audio when it is a WAV file
+func (c *HearCmd) writeCache(cfg *config.AppConfig, key string, t *provider.Transcript, wavData []byte) {
+	data := []byte(t.Text)
+	if c.timed() {
+		var err error
+		if data, err = json.Marshal(toJSON(t)); err != nil {
+			return
+		}
+	}
+	path := c.cachePath(cfg, key)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return
+	}
+	source := c.File
+	if source == "-" {
+		source = "stdin"
+	}
+	e := cache.Entry{
+		Hash:   key,
+		File:   filepath.Base(path),
+		Kind:   cache.KindTranscript,
+		Text:   t.Text,
+		Source: source,
+	}
+	if a, err := wav.Decode(wavData); err == nil && a.Format.SampleRate > 0 {
+		e.Duration = float64(len(a.Mono())) / float64(a.Format.SampleRate)
+	}
+	cache.Add(filepath.Dir(path), e)
+}
+
+// runLive streams microphone audio to a realtime transcriber, showing the
+// interim transcript on stderr and printing the final text to stdout.
+func (c *HearCmd) runLive(st provider.StreamingTranscriber) error {
+	var vad *audio.VAD
+	if c.VAD {
+		vad = audio.NewVAD(asrSampleRate, c.vadConfig())
+		ui.Info("%s %s", vadBanner(c.Duration), ui.Dim("(speak now, Ctrl+C to stop)"))
+	} else {
+		ui.Info("%s %s", recordingBanner(c.Duration), ui.Dim("(speak now, Ctrl+C to stop)"))
+	}
+
+	recorder, err := audio.NewRecorder(asrSampleRate, 1, c.inputDevice)
+	if err != nil {
+		return fmt.Errorf("init recorder: %w", err)
+	}
+
+	limit := time.Duration(c.Duration) * time.Second
+	if vad != nil {
+		limit = 0
+	}
+	meter := meterRecorder(recorder, limit)
+
+	// If the uplink stalls, the recorder keeps the latest audio rather than
+	// letting it pile up
+	recorder.Buffer(liveBuffer, audio.DropOldest)
+	if vad != nil {
+		recorder.OnData(vad.Write)
+	}
+	chunks := make(chan []byte)
 
+	session, cancelSession := context.WithCancel(context.Background())
+	defer cancelSession()
+	results := make(chan liveOutcome, 1)
+	go func() {
+		result, err := st.StreamTranscribe(session, chunks, c.Context, meter.setText)
+		results <- liveOutcome{result, err}
+	}()
+
+	if err := recorder.Start(); err != nil {
+		recorder.Close()
+		close(chunks)
+		<-results
+		return fmt.Errorf("start recording: %w", err)
+	}
+	// Send the audio on in 100ms chunks until the recorder is closed and
+	// drained
+	go func() {
+		defer close(chunks)
+		buf := make([]byte, liveChunk)
+		for {
+			n, err := io.ReadFull(recorder, buf)
+			if n > 0 {
+				chunks <- append([]byte(nil), buf[:n]...)
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	stop, cancel := c.stopContext(vad)
+	defer cancel()
+
+	var out liveOutcome
+	select {
+	case <-stop.Done():
+		recorder.Close()
+		// Hand Ctrl+C back, so a second one abandons the final transcript
+		cancel()
+		t0 := time.Now()
+		out = awaitTranscript(results, cancelSession)
+		meter.finish()
+		ui.Info("%s %s", ui.Dim("latency"), ui.Dim(time.Since(t0).Round(time.Millisecond).String()))
+	case out = <-results:
+		// Session ended early (usually an error); stop capturing
+		recorder.Close()
+		cancel()
+		meter.finish()
+	}
+	// Let the sender finish if the session is no longer reading
+	go func() {
+		for range chunks {
+		}
+	}()
+	if lost := recorder.Dropped(); lost > 0 {
+		ui.Warn("Transcription fell behind; %s of audio was dropped", lost.Round(time.Millisecond))
+	}
+
+	if out.err != nil {
+		return fmt.Errorf("transcribe: %w", out.err)
+	}
+
+	fmt.Println(out.result.Text)
 	return nil
 }
 
-// wrapPCMAsWAVWithRate wraps raw PCM 16-bit mono data in a WAV container at the given sample rate
-func wrapPCMAsWAVWithRate(pcm []byte, sampleRate int) []byte {
-	dataLen := uint32(len(pcm))
-	fileLen := dataLen + 36
-	sr := uint32(sampleRate)
-	br := sr * 2 // 16-bit mono
-
-	header := []byte{
-		'R', 'I', 'F', 'F',
-		byte(fileLen), byte(fileLen >> 8), byte(fileLen >> 16), byte(fileLen >> 24),
-		'W', 'A', 'V', 'E',
-		'f', 'm', 't', ' ',
-		16, 0, 0, 0,
-		1, 0, // PCM
-		1, 0, // mono
-		byte(sr), byte(sr >> 8), byte(sr >> 16), byte(sr >> 24),
-		byte(br), byte(br >> 8), byte(br >> 16), byte(br >> 24),
-		2, 0,  // block align
-		16, 0, // bits per sample
-		'd', 'a', 't', 'a',
-		byte(dataLen), byte(dataLen >> 8), byte(dataLen >> 16), byte(dataLen >> 24),
-	}
-
-	return append(header, pcm...)
+// liveOutcome is how a realtime transcription session ended
+type liveOutcome struct {
+	result *provider.Transcript
+	err    error
+}
+
+// awaitTranscript waits for the final transcript once recording has
+// stopped. Ctrl+C or a server that stays silent for finalTimeout cancels
+// the session instead of leaving vox hanging.
+func awaitTranscript(results <-chan liveOutcome, cancelSession context.CancelFunc) liveOutcome {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	ctx, cancelTimeout := context.WithTimeout(ctx, finalTimeout)
+	defer cancelTimeout()
+
+	select {
+	case out := <-results:
+		return out
+	case <-ctx.Done():
+		// Don't wait on the session; it may be stuck on the network
+		cancelSession()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return liveOutcome{err: fmt.Errorf("no final transcript after %s", finalTimeout)}
+		}
+		return liveOutcome{err: errors.New("stopped waiting for the final transcript")}
+	}
+}
+
+// recordingContext is cancelled after seconds, or on Ctrl+C. Zero seconds
+// records until Ctrl+C.
+func recordingContext(seconds int) (context.Context, context.CancelFunc) {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	if seconds <= 0 {
+		return ctx, cancel
+	}
+	ctx, cancelTimeout := context.WithTimeout(ctx, time.Duration(seconds)*time.Second)
+	return ctx, func() {
+		cancelTimeout()
+		cancel()
+	}
+}
+
+// stopContext ends a recording: after -d seconds, or with --vad once the
+// speaker pauses (or hasn't started talking within -d seconds)
+func (c *HearCmd) stopContext(vad *audio.VAD) (context.Context, context.CancelFunc) {
+	if vad == nil {
+		return recordingContext(c.Duration)
+	}
+	return speechContext(vad, time.Duration(c.Duration)*time.Second)
+}
+
+// speechContext is cancelled when the VAD hears the speaker finish, on
+// Ctrl+C, or if no speech starts within wait (zero waits forever)
+func speechContext(vad *audio.VAD, wait time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	var timeout <-chan time.Time
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		timeout = timer.C
+	}
+	go func() {
+		for {
+			select {
+			case <-vad.Done():
+				cancel()
+				return
+			case <-timeout:
+				if !vad.Started() {
+					cancel()
+					return
+				}
+				timeout = nil
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ctx, cancel
+}
+
+func vadBanner(seconds int) string {
+	if seconds <= 0 {
+		return "Listening until you pause..."
+	}
+	return fmt.Sprintf("Listening until you pause (waits %ds for speech)...", seconds)
+}
+
+func recordingBanner(seconds int) string {
+	if seconds <= 0 {
+		return "Recording until Ctrl+C..."
+	}
+	return fmt.Sprintf("Recording for %ds...", seconds)
 }
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ontypehq/vox/internal/audio"
+	"github.com/ontypehq/vox/internal/ui"
+)
+
+// playbackKeys controls whatever is playing from the keyboard: space pauses
+// and resumes, s skips (when enabled), q and Ctrl+C quit. Without keys (no
+// terminal, or stdin in use) it only stops playback when ctx ends, which is
+// how Ctrl+C arrives then.
+type playbackKeys struct {
+	ctx     context.Context
+	quit    context.CancelFunc
+	canSkip bool
+	restore func()
+
+	mu     sync.Mutex
+	sink   audio.Sink
+	cancel context.CancelFunc // stops whatever feeds the current sink
+}
+
+func bindPlaybackKeys(ctx context.Context, quit context.CancelFunc, canSkip, keyboard bool) *playbackKeys {
+	var keys <-chan byte
+	restore := func() {}
+	if keyboard {
+		keys, restore = ui.ReadKeys()
+	}
+	k := &playbackKeys{ctx: ctx, quit: quit, canSkip: canSkip, restore: restore}
+	go k.loop(keys)
+	return k
+}
+
+// play makes s the sink the keys act on; cancel is called when it is
+// stopped, to abandon the synthesis feeding it
+func (k *playbackKeys) play(s audio.Sink, cancel context.CancelFunc) {
+	k.mu.Lock()
+	k.sink, k.cancel = s, cancel
+	k.mu.Unlock()
+}
+
+// done forgets the current sink
+func (k *playbackKeys) done() {
+	k.play(nil, nil)
+	ui.ClearStatus()
+}
+
+// close restores the terminal
+func (k *playbackKeys) close() {
+	k.restore()
+}
+
+func (k *playbackKeys) loop(keys <-chan byte) {
+	for {
+		select {
+		case <-k.ctx.Done():
+			k.stop()
+			return
+		case key := <-keys:
+			switch key {
+			case ' ':
+				k.togglePause()
+			case 's', 'S':
+				if k.canSkip {
+					k.stop()
+				}
+			case 'q', 'Q', ui.KeyInterrupt:
+				k.stop()
+				k.quit()
+				return
+			}
+		}
+	}
+}
+
+func (k *playbackKeys) togglePause() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	p, ok := k.sink.(audio.Player)
+	if !ok {
+		return // not playing in real time
+	}
+	if p.Paused() {
+		p.Resume()
+	} else {
+		p.Pause()
+	}
+	ui.Status("%s", progressLine(p.Position(), p.Duration(), p.Paused()))
+}
+
+// stop silences the current sink and cancels its synthesis
+func (k *playbackKeys) stop() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.sink != nil {
+		k.sink.Stop()
+	}
+	if k.cancel != nil {
+		k.cancel()
+	}
+	ui.ClearStatus()
+}
+
+// showProgress keeps elapsed and remaining time, and a bar, on the status
+// line while s plays. With streamed synthesis the total grows as audio
+// arrives. Sinks that don't play in real time show nothing.
+func showProgress(s audio.Sink) {
+	p, ok := s.(audio.Player)
+	if !ok || !ui.IsTerminal() {
+		return
+	}
+	p.OnProgress(func(pos, total time.Duration) {
+		ui.Status("%s", progressLine(pos, total, p.Paused()))
+	})
+}
+
+func progressLine(pos, total time.Duration, paused bool) string {
+	const width = 24
+	filled := 0
+	if total > 0 {
+		filled = min(int(int64(width)*int64(pos)/int64(total)), width)
+	}
+	icon := "▶"
+	if paused {
+		icon = "⏸"
+	}
+	line := fmt.Sprintf("%s %s %s%s %s  -%s", icon, clock(pos), strings.Repeat("━", filled), strings.Repeat("─", width-filled), clock(total), clock(total-pos))
+	if paused {
+		line += "  (space to resume)"
+	}
+	return line
+}
+
+// clock formats a duration as m:ss
+func clock(d time.Duration) string {
+	s := int(d.Round(time.Second) / time.Second)
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
@@ -12,7 +12,8 @@ import (
 
 	"github.com/ontypehq/vox/internal/audio"
 	"github.com/ontypehq/vox/internal/config"
-	"github.com/ontypehq/vox/internal/dashscope"
+	"github.com/ontypehq/vox/internal/provider"
+	"github.com/ontypehq/vox/internal/segment"
 	"github.com/ontypehq/vox/internal/ui"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
@@ -28,15 +29,27 @@ type ListenCmd struct {
 }
 
 func (c *ListenCmd) Run(cfg *config.AppConfig) error {
-	apiKey, err := cfg.RequireAPIKey()
+	p, err := provider.Open(cfg)
 	if err != nil {
 		return err
 	}
+	defer p.Close()
 	botToken, appToken, err := cfg.RequireSlack()
 	if err != nil {
 		return err
 	}
 
+	// Fail now rather than on the first message if the device is missing
+	if outputDevice := cfg.OutputDevice(); outputDevice != "" && playsOnDevice(cfg) {
+		outputs, err := audio.OutputDevices()
+		if err != nil {
+			return err
+		}
+		if _, err := audio.FindDevice(outputs, outputDevice); err != nil {
+			return fmt.Errorf("output device: %w", err)
+		}
+	}
+
 	// Default voice
 	defaultVoice := c.Voice
 	if defaultVoice == "" {
@@ -47,7 +60,7 @@ func (c *ListenCmd) Run(cfg *config.AppConfig) error {
 	}
 
 	api := slack.New(botToken, slack.OptionAppLevelToken(appToken))
-	client := socketmode.New(api, socketmode.OptionLog(log.New(os.Stderr, "", 0)))
+	client := socketmode.New(api, socketmode.OptionLog(log.New(ui.Writer(), "", 0)))
 
 	// Get bot's own user ID to skip self messages
 	authResp, err := api.AuthTest()
@@ -114,7 +127,7 @@ func (c *ListenCmd) Run(cfg *config.AppConfig) error {
 	voiceMap := cfg.Config.Listen.VoiceMap
 
 	// Returns (voice, mapped). mapped=true means the user has a dedicated voice.
-	resolveVoice := func(userID, displayName string) (string, bool) {
+	resolveVoice := func(userID, displayName string) (config.VoiceMapping, bool) {
 		if v, ok := voiceMap[userID]; ok {
 			return v, true
 		}
@@ -127,11 +140,9 @@ func (c *ListenCmd) Run(cfg *config.AppConfig) error {
 				return v, true
 			}
 		}
-		return defaultVoice, false
+		return config.VoiceMapping{Voice: defaultVoice}, false
 	}
 
-	ttsClient := dashscope.NewRealtimeClient(apiKey)
-
 	ui.Success("Listening on Slack")
 	ui.KV("Voice", defaultVoice)
 	if len(filterChannels) > 0 {
@@ -145,10 +156,16 @@ func (c *ListenCmd) Run(cfg *config.AppConfig) error {
 	if len(voiceMap) > 0 {
 		ui.KV("Voice map", fmt.Sprintf("%d users", len(voiceMap)))
 	}
-	ui.Info("%s", ui.Dim("Ctrl+C to stop"))
+	if ui.IsTerminal() {
+		ui.Info("%s", ui.Dim("space pause · s skip message · q or Ctrl+C quit"))
+	} else {
+		ui.Info("%s", ui.Dim("Ctrl+C to stop"))
+	}
 
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
+	keys := bindPlaybackKeys(ctx, cancel, true, true)
+	defer keys.close()
 
 	go func() {
 		for evt := range client.Events {
@@ -185,8 +202,9 @@ func (c *ListenCmd) Run(cfg *config.AppConfig) error {
 					text = cleanSlackText(text)
 					sender := getName(ev.User)
 					chName := getChannelName(ev.Channel)
-					voice, mapped := resolveVoice(ev.User, sender)
-					model := dashscope.ModelForVoice(voice)
+					mapping, mapped := resolveVoice(ev.User, sender)
+					voice := mapping.Voice
+					model := p.TTSModel(voice, false)
 
 					// If voice is mapped to this user, skip the "from X in Y" fence —
 					// the voice itself identifies who's speaking.
@@ -205,26 +223,52 @@ func (c *ListenCmd) Run(cfg *config.AppConfig) error {
 						text,
 					)
 
+					// Speak it; long messages go out in pipelined segments
+					player, err := openSink(cfg)
+					if err != nil {
+						ui.Warn("Playback failed: %v", err)
+						continue
+					}
+
 					// Chime before message (skip if voice is mapped)
 					if !c.NoChime && !mapped {
-						playChime()
+						player.Write(chime())
 					}
-
-					// Speak it
-					player := audio.NewStreamPlayer()
-					opts := dashscope.TTSOptions{
+					opts := provider.TTSOptions{
 						Model:      model,
 						Voice:      voice,
-						Text:       spoken,
 						Lang:       "auto",
 						SpeechRate: c.Speed,
+						Volume:     mapping.Volume,
+						PitchRate:  mapping.Pitch,
+					}
+					// Voices differ in level; bring each message to the
+					// same loudness if asked
+					write := player.Write
+					var norm *audio.Normalizer
+					if lufs := cfg.Loudness(); lufs != 0 {
+						norm = audio.NewNormalizer(audio.SampleRate, lufs, player.Write)
+						write = norm.Write
+					}
+					segments := segment.Split(spoken, segment.DefaultMaxLen)
+					msgCtx, skip := context.WithCancel(ctx)
+					keys.play(player, skip)
+					showProgress(player)
+					err = synthesizeSegments(msgCtx, p, opts, segments, 2, write)
+					if norm != nil {
+						norm.Flush()
 					}
-					ttsCtx, ttsCancel := context.WithTimeout(context.Background(), 30*time.Second)
-					ttsClient.StreamTTS(ttsCtx, opts, func(pcm []byte) {
-						player.Write(pcm)
-					})
 					player.Close()
-					ttsCancel()
+					keys.done()
+					skipped := msgCtx.Err() != nil
+					skip()
+					if skipped {
+						if ctx.Err() == nil {
+							ui.Info("%s", ui.Dim("skipped"))
+						}
+					} else if err != nil {
+						ui.Warn("TTS failed: %v", err)
+					}
 				}
 
 			case socketmode.EventTypeConnectionError:
@@ -265,8 +309,8 @@ func resolveChannel(api *slack.Client, channel string) string {
 	return channel
 }
 
-// playChime generates a short notification tone (two-tone chime)
-func playChime() {
+// chime generates a short notification tone (two-tone chime)
+func chime() []byte {
 	const (
 		sampleRate = audio.SampleRate // 24000
 		duration   = 120             // ms total
@@ -300,9 +344,7 @@ func playChime() {
 		pcm[i*2+1] = byte(sample >> 8)
 	}
 
-	player := audio.NewStreamPlayer()
-	player.Write(pcm)
-	player.Close()
+	return pcm
 }
 
 // cleanSlackText removes slack markup like <@U123> mentions, <url|label> links, etc.
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ontypehq/vox/internal/audio"
+	"github.com/ontypehq/vox/internal/ui"
+)
+
+const (
+	// meterInterval is how often the level meter is redrawn
+	meterInterval = 50 * time.Millisecond
+	// meterFloor is the level (dBFS) at which the meter bar is empty
+	meterFloor = -60.0
+	// silenceGrace is how long the input may stay dead before the meter
+	// says so
+	silenceGrace = 2 * time.Second
+	// clipHold keeps the clipping mark up long enough to be noticed
+	clipHold = time.Second
+)
+
+// levelMeter shows the input level and recording time on the status line
+// while recording, and warns afterwards if the level was off
+type levelMeter struct {
+	limit time.Duration // counted down to; zero shows elapsed time only
+	stats audio.LevelStats
+
+	// Written from the capture thread, which must never wait on the
+	// terminal
+	start atomic.Int64  // first block, unix nanoseconds
+	peak  atomic.Uint64 // float64 bits of the highest peak since the last redraw
+	clip  atomic.Int64  // last clipped block, unix nanoseconds
+
+	mu   sync.Mutex
+	text string // shown before the meter, e.g. the live transcript
+	done bool
+
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// meterRecorder attaches a level meter to recorder. Call finish once
+// recording stops.
+func meterRecorder(recorder *audio.Recorder, limit time.Duration) *levelMeter {
+	m := &levelMeter{limit: limit, stop: make(chan struct{}), stopped: make(chan struct{})}
+	recorder.OnLevel(m.update)
+	go m.run()
+	return m
+}
+
+// update records a block's level. It runs on the capture thread, so it
+// only stores the level; run draws it.
+func (m *levelMeter) update(l audio.Level) {
+	m.stats.Add(l)
+	now := time.Now().UnixNano()
+	m.start.CompareAndSwap(0, now)
+	for {
+		old := m.peak.Load()
+		if l.Peak <= math.Float64frombits(old) || m.peak.CompareAndSwap(old, math.Float64bits(l.Peak)) {
+			break
+		}
+	}
+	if l.Clipping() {
+		m.clip.Store(now)
+	}
+}
+
+// run redraws the meter every meterInterval once audio is coming in
+func (m *levelMeter) run() {
+	defer close(m.stopped)
+	ticker := time.NewTicker(meterInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.stop:
+			return
+		case now := <-ticker.C:
+			if m.start.Load() == 0 {
+				continue
+			}
+			m.mu.Lock()
+			m.draw(now)
+			m.mu.Unlock()
+		}
+	}
+}
+
+// setText puts text in front of the meter
+func (m *levelMeter) setText(text string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.text = text
+	m.draw(time.Now())
+}
+
+func (m *levelMeter) draw(now time.Time) {
+	if m.done {
+		return
+	}
+	var elapsed time.Duration
+	if start := m.start.Load(); start != 0 {
+		elapsed = now.Sub(time.Unix(0, start))
+	}
+	line := meterLine(math.Float64frombits(m.peak.Swap(0)), elapsed, m.limit)
+	switch clip := m.clip.Load(); {
+	case clip != 0 && now.Sub(time.Unix(0, clip)) < clipHold:
+		line += "  clipping!"
+	case elapsed > silenceGrace && m.stats.Silent():
+		line += "  no signal"
+	}
+	if m.text != "" {
+		line = m.text + "  " + line
+	}
+	ui.Status("%s", line)
+}
+
+// finish clears the meter and warns if the input was silent, too quiet or
+// clipping
+func (m *levelMeter) finish() {
+	close(m.stop)
+	<-m.stopped
+	m.mu.Lock()
+	m.done = true
+	m.mu.Unlock()
+	ui.ClearStatus()
+	if problem := m.stats.Problem(); problem != "" {
+		ui.Warn("%s", problem)
+	}
+}
+
+// meterLine renders a peak level bar and the recording clock, counting down
+// when there is a limit
+func meterLine(peak float64, elapsed, limit time.Duration) string {
+	const width = 20
+	db := max(audio.DB(peak), meterFloor)
+	filled := int((db - meterFloor) / -meterFloor * width)
+
+	line := fmt.Sprintf("● %s", clock(elapsed))
+	if limit > 0 {
+		line += fmt.Sprintf("  -%s", clock(max(limit-elapsed, 0)))
+	}
+	return line + fmt.Sprintf("  ▕%s%s▏ %4.0f dB", strings.Repeat("█", filled), strings.Repeat("░", width-filled), db)
+}
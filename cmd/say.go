@@ -5,31 +5,71 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/ontypehq/vox/internal/audio"
+	"github.com/ontypehq/vox/internal/cache"
 	"github.com/ontypehq/vox/internal/config"
-	"github.com/ontypehq/vox/internal/dashscope"
+	"github.com/ontypehq/vox/internal/provider"
+	"github.com/ontypehq/vox/internal/segment"
 	"github.com/ontypehq/vox/internal/ui"
 )
 
 type SayCmd struct {
-	Text     string  `arg:"" help:"Text to speak"`
+	Text     string  `arg:"" optional:"" help:"Text to speak"`
 	Voice    string  `short:"v" help:"Voice ID (system name or cloned voice ID)"`
 	Lang     string  `short:"l" default:"auto" help:"Language hint (auto, Chinese, English, Japanese, ...)"`
 	Instruct string  `short:"i" help:"Voice style instruction (e.g. 'warm and expressive, moderate pace')"`
 	Speed    float64 `short:"s" default:"1.0" help:"Speech rate (0.5-2.0)"`
-	Output   string  `short:"o" help:"Save audio to file instead of playing"`
+	Volume   int     `default:"50" help:"Volume (1-100)"`
+	Pitch    float64 `default:"1.0" help:"Pitch (0.5-2.0)"`
+	Output   string  `short:"o" help:"Save audio to file ('-' streams to stdout)"`
+	Format   string  `help:"Output file format (default: from -o extension, else wav). See 'vox formats'"`
+	Rate     int     `help:"Output sample rate in Hz (default: 24000, or the format's fixed rate)"`
+	Stream   bool    `help:"Read text from stdin and start speaking at the first sentence"`
+	Parallel int     `default:"3" help:"Segments of long text synthesized concurrently"`
+	Play     bool    `help:"Also play audio when streaming it to stdout with -o -"`
 	NoCache  bool    `help:"Skip audio cache"`
+
+	outFormat audio.OutputFormat
+	ctx       context.Context // cancelled by Ctrl+C or q
+	keys      *playbackKeys
 }
 
 func (c *SayCmd) Run(cfg *config.AppConfig) error {
-	apiKey, err := cfg.RequireAPIKey()
+	p, err := provider.Open(cfg)
 	if err != nil {
 		return err
 	}
+	defer p.Close()
+
+	if c.Volume < 1 || c.Volume > 100 {
+		return fmt.Errorf("--volume must be between 1 and 100")
+	}
+	if c.Pitch < 0.5 || c.Pitch > 2.0 {
+		return fmt.Errorf("--pitch must be between 0.5 and 2.0")
+	}
+
+	// Fail before synthesizing if -o can't be written
+	if c.Output != "" {
+		if err := c.resolveFormat(); err != nil {
+			return err
+		}
+	}
+
+	// Ctrl+C or q stops playback at once and abandons synthesis. Keys are
+	// read only when stdin isn't carrying the text.
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	c.ctx = ctx
+	c.keys = bindPlaybackKeys(ctx, cancel, false, !c.Stream)
+	defer c.keys.close()
 
 	// Resolve voice
 	voice := c.Voice
@@ -41,90 +81,202 @@ func (c *SayCmd) Run(cfg *config.AppConfig) error {
 	}
 
 	// Pick model based on voice type and instruct mode
-	model := dashscope.ModelForVoice(voice)
-	if c.Instruct != "" && dashscope.IsSystemVoice(voice) {
-		model = dashscope.ModelInstructRealtime
+	model := p.TTSModel(voice, c.Instruct != "")
+
+	if c.Stream {
+		if c.Text != "" {
+			return fmt.Errorf("--stream reads text from stdin; don't pass text as an argument")
+		}
+		if inc, ok := p.(provider.IncrementalSynthesizer); ok {
+			return c.runStream(cfg, inc, voice, model)
+		}
+		// Provider can't take partial input: read it all, then speak
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("read stdin: %w", err)
+		}
+		c.Text = strings.TrimSpace(string(data))
+	}
+	if c.Text == "" {
+		return fmt.Errorf("no text to speak (pass it as an argument, or pipe it with --stream)")
 	}
 
 	// Check cache
-	cacheKey := fmt.Sprintf("%s:%s:%s:%s:%s:%.1f", model, voice, c.Lang, c.Instruct, c.Text, c.Speed)
-	cacheHash := sha256.Sum256([]byte(cacheKey))
-	hashStr := hex.EncodeToString(cacheHash[:])
-	cachePath := filepath.Join(cfg.Dir, "cache", hashStr+".opus")
-
+	hashStr := c.cacheHash(cfg, model, voice)
 	if !c.NoCache {
-		if opusData, err := os.ReadFile(cachePath); err == nil {
+		if pcmData, file, ok := readCachedAudio(cfg, hashStr); ok {
 			ui.Info("%s %s", ui.Dim("cached"), ui.Dim(voice))
-			pcmData, err := audio.DecodeOpusToPCM(opusData)
+			cache.Touch(filepath.Join(cfg.Dir, "cache"), file)
+			out, err := c.openOutput(cfg, false)
 			if err != nil {
-				// Fallback: try legacy .pcm cache
-				legacyPath := filepath.Join(cfg.Dir, "cache", hashStr+".pcm")
-				if pcmData, err = os.ReadFile(legacyPath); err != nil {
-					return fmt.Errorf("decode cache: %w", err)
-				}
+				return err
+			}
+			out.Write(pcmData)
+			out.Close()
+			if c.stopped() {
+				return nil
 			}
-			return playPCM(pcmData, c.Output)
+			return c.save(out)
 		}
-		// Fallback: try legacy .pcm cache
-		legacyPath := filepath.Join(cfg.Dir, "cache", hashStr+".pcm")
-		if data, err := os.ReadFile(legacyPath); err == nil {
-			ui.Info("%s %s", ui.Dim("cached"), ui.Dim(voice))
-			return playPCM(data, c.Output)
+	}
+
+	// Stream from API, long text in pipelined segments
+	segments := segment.Split(c.Text, segment.DefaultMaxLen)
+	if len(segments) > 1 {
+		ui.Info("%s %s %s %s", ui.Dim("voice"), ui.Key(voice), ui.Dim("("+model+")"), ui.Dim(fmt.Sprintf("%d segments", len(segments))))
+	} else {
+		ui.Info("%s %s %s", ui.Dim("voice"), ui.Key(voice), ui.Dim("("+model+")"))
+	}
+
+	out, err := c.openOutput(cfg, true)
+	if err != nil {
+		return err
+	}
+
+	t0 := time.Now()
+	var firstChunk bool
+
+	opts := provider.TTSOptions{
+		Model:      model,
+		Voice:      voice,
+		Lang:       c.Lang,
+		Instruct:   c.Instruct,
+		SpeechRate: c.Speed,
+		Volume:     c.Volume,
+		PitchRate:  c.Pitch,
+	}
+	err = synthesizeSegments(c.ctx, p, opts, segments, c.Parallel, func(pcm []byte) {
+		if !firstChunk {
+			firstChunk = true
+			ui.Info("%s %s", ui.Dim("first audio"), ui.Dim(time.Since(t0).Round(time.Millisecond).String()))
 		}
+		out.Write(pcm)
+	})
+
+	out.Close()
+
+	if c.stopped() {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("TTS stream: %w", err)
 	}
 
-	// Stream from API
-	ui.Info("%s %s %s", ui.Dim("voice"), ui.Key(voice), ui.Dim("("+model+")"))
+	return c.finish(cfg, model, voice, hashStr, out)
+}
 
-	client := dashscope.NewRealtimeClient(apiKey)
-	player := audio.NewStreamPlayer()
-	collector := &audio.PCMCollector{}
+// runStream speaks stdin as it arrives, committing text sentence by sentence
+func (c *SayCmd) runStream(cfg *config.AppConfig, p provider.IncrementalSynthesizer, voice, model string) error {
+	ui.Info("%s %s %s %s", ui.Dim("voice"), ui.Key(voice), ui.Dim("("+model+")"), ui.Dim("reading stdin"))
+
+	out, err := c.openOutput(cfg, true)
+	if err != nil {
+		return err
+	}
 
 	t0 := time.Now()
 	var firstChunk bool
 
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
-	defer cancel()
+	text := make(chan string)
+	var full strings.Builder
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(text)
+		readErr <- readTextChunks(os.Stdin, func(chunk string) {
+			full.WriteString(chunk)
+			text <- chunk
+		})
+	}()
 
-	opts := dashscope.TTSOptions{
-		Model:       model,
-		Voice:       voice,
-		Text:        c.Text,
-		Lang:        c.Lang,
-		Instruct:    c.Instruct,
-		SpeechRate:  c.Speed,
+	opts := provider.TTSOptions{
+		Model:      model,
+		Voice:      voice,
+		Lang:       c.Lang,
+		Instruct:   c.Instruct,
+		SpeechRate: c.Speed,
+		Volume:     c.Volume,
+		PitchRate:  c.Pitch,
 	}
-	err = client.StreamTTS(ctx, opts, func(pcm []byte) {
+	err = p.StreamTTSInput(c.ctx, opts, text, func(pcm []byte) {
 		if !firstChunk {
 			firstChunk = true
 			ui.Info("%s %s", ui.Dim("first audio"), ui.Dim(time.Since(t0).Round(time.Millisecond).String()))
 		}
-		player.Write(pcm)
-		collector.Write(pcm)
+		out.Write(pcm)
 	})
 
-	player.Close()
+	out.Close()
 
-	if err != nil {
+	if err != nil || c.ctx.Err() != nil {
+		// Unblock the reader if the stream failed mid-input
+		go func() {
+			for range text {
+			}
+		}()
+		if c.stopped() {
+			return nil
+		}
 		return fmt.Errorf("TTS stream: %w", err)
 	}
+	if err := <-readErr; err != nil {
+		return fmt.Errorf("read stdin: %w", err)
+	}
 
-	// Cache the result as opus
-	if !c.NoCache && len(collector.Bytes()) > 0 {
-		if opusData, err := audio.EncodePCMToOpus(collector.Bytes()); err == nil {
-			os.WriteFile(cachePath, opusData, 0644)
-		} else {
-			// Fallback to raw PCM if ffmpeg unavailable
-			os.WriteFile(filepath.Join(cfg.Dir, "cache", hashStr+".pcm"), collector.Bytes(), 0644)
+	// Cache under the full text so a repeat `vox say "<text>"` is instant
+	c.Text = strings.TrimSpace(full.String())
+	return c.finish(cfg, model, voice, c.cacheHash(cfg, model, voice), out)
+}
+
+// stopped reports whether the user stopped playback; nothing is cached or
+// saved then, since the audio is incomplete
+func (c *SayCmd) stopped() bool {
+	if c.ctx.Err() == nil {
+		return false
+	}
+	ui.Info("%s", ui.Dim("stopped"))
+	return true
+}
+
+func (c *SayCmd) cacheHash(cfg *config.AppConfig, model, voice string) string {
+	cacheKey := fmt.Sprintf("%s:%s:%s:%s:%s:%.1f", model, voice, c.Lang, c.Instruct, c.Text, c.Speed)
+	// Only non-default settings extend the key, so existing entries still hit
+	if c.Volume != 50 || c.Pitch != 1.0 {
+		cacheKey += fmt.Sprintf(":v%d:p%.2f", c.Volume, c.Pitch)
+	}
+	if lufs := cfg.Loudness(); lufs != 0 {
+		cacheKey += fmt.Sprintf(":%.1fLUFS", lufs)
+	}
+	cacheHash := sha256.Sum256([]byte(cacheKey))
+	return hex.EncodeToString(cacheHash[:])
+}
+
+// finish caches synthesized audio, writes -o, and remembers the voice
+func (c *SayCmd) finish(cfg *config.AppConfig, model, voice, hashStr string, out *sayOutput) error {
+	// Cache the result as Ogg Opus or FLAC, indexed so vox cache can find it
+	if pcm := out.collector.Bytes(); !c.NoCache && len(pcm) > 0 {
+		dir := filepath.Join(cfg.Dir, "cache")
+		if data, err := audio.EncodePCMToOpus(pcm); err == nil && os.WriteFile(filepath.Join(dir, hashStr+".ogg"), data, 0644) == nil {
+			cache.Add(dir, cache.Entry{
+				Hash:     hashStr,
+				File:     hashStr + ".ogg",
+				Kind:     cache.KindSpeech,
+				Text:     c.Text,
+				Voice:    voice,
+				Model:    model,
+				Lang:     c.Lang,
+				Instruct: c.Instruct,
+				Speed:    c.Speed,
+				Volume:   c.Volume,
+				Pitch:    c.Pitch,
+				Loudness: cfg.Loudness(),
+				Duration: float64(len(pcm)) / (audio.SampleRate * 2),
+			})
 		}
 	}
 
 	// Save output file if requested
-	if c.Output != "" {
-		if err := writePCMAsWAV(c.Output, collector.Bytes()); err != nil {
-			return fmt.Errorf("save: %w", err)
-		}
-		ui.Success("Saved to %s", c.Output)
+	if err := c.save(out); err != nil {
+		return err
 	}
 
 	// Update state
@@ -137,48 +289,168 @@ func (c *SayCmd) Run(cfg *config.AppConfig) error {
 	return nil
 }
 
-func playPCM(data []byte, outputPath string) error {
-	player := audio.NewStreamPlayer()
-	player.Write(data)
-	player.Close()
+// resolveFormat picks the -o format from --format or the file extension
+// and checks that it can be written here
+func (c *SayCmd) resolveFormat() error {
+	f := audio.FormatForPath(c.Output)
+	if c.Format != "" {
+		var err error
+		if f, err = audio.LookupFormat(c.Format); err != nil {
+			return err
+		}
+	}
+	if f.FixedRate != 0 && c.Rate != 0 && c.Rate != f.FixedRate {
+		return fmt.Errorf("%s is only defined at %d Hz", f.Name, f.FixedRate)
+	}
+	c.outFormat = f
+	return f.Available()
+}
 
-	if outputPath != "" {
-		return writePCMAsWAV(outputPath, data)
+// save writes the audio to -o in the chosen format, unless it was already
+// streamed to stdout as it arrived
+func (c *SayCmd) save(out *sayOutput) error {
+	if c.Output == "" {
+		return nil
 	}
+	if out.err != nil {
+		return fmt.Errorf("write stdout: %w", out.err)
+	}
+	if out.stdout != nil {
+		return nil
+	}
+	data, err := audio.EncodeAs(c.outFormat, out.collector.Bytes(), audio.SampleRate, c.Rate)
+	if err != nil {
+		return fmt.Errorf("save: %w", err)
+	}
+	if c.Output == "-" {
+		if _, err := os.Stdout.Write(data); err != nil {
+			return fmt.Errorf("write stdout: %w", err)
+		}
+		return nil
+	}
+	if err := os.WriteFile(c.Output, data, 0644); err != nil {
+		return fmt.Errorf("save: %w", err)
+	}
+	ui.Success("Saved to %s %s", c.Output, ui.Dim("("+c.outFormat.Name+")"))
 	return nil
 }
 
-func writePCMAsWAV(path string, pcm []byte) error {
-	f, err := os.Create(path)
-	if err != nil {
-		return err
+// sayOutput fans synthesized audio out to the speaker, the -o - stream and
+// the collector that feeds the cache and -o files, after loudness
+// normalization if it is on
+type sayOutput struct {
+	norm      *audio.Normalizer
+	sink      audio.Sink
+	stdout    io.Writer // set when audio streams to stdout as it arrives
+	collector audio.PCMCollector
+	err       error // first stdout write error
+}
+
+// openOutput starts playback, unless audio goes to stdout without --play.
+// With -o -, formats that are plain PCM behind a header (wav, pcm) stream
+// chunk by chunk; anything else is written once synthesis finishes. Cached
+// audio is already normalized, so normalize is false for it.
+func (c *SayCmd) openOutput(cfg *config.AppConfig, normalize bool) (*sayOutput, error) {
+	out := &sayOutput{}
+	if lufs := cfg.Loudness(); normalize && lufs != 0 {
+		out.norm = audio.NewNormalizer(audio.SampleRate, lufs, out.write)
 	}
-	defer f.Close()
-
-	// Write WAV header
-	dataLen := uint32(len(pcm))
-	fileLen := dataLen + 36
-	sampleRate := uint32(audio.SampleRate)
-	byteRate := sampleRate * 2 // 16-bit mono = 2 bytes per sample
-	header := []byte{
-		'R', 'I', 'F', 'F',
-		byte(fileLen), byte(fileLen >> 8), byte(fileLen >> 16), byte(fileLen >> 24),
-		'W', 'A', 'V', 'E',
-		'f', 'm', 't', ' ',
-		16, 0, 0, 0, // chunk size
-		1, 0, // PCM format
-		1, 0, // mono
-		byte(sampleRate), byte(sampleRate >> 8), byte(sampleRate >> 16), byte(sampleRate >> 24),
-		byte(byteRate), byte(byteRate >> 8), byte(byteRate >> 16), byte(byteRate >> 24),
-		2, 0, // block align
-		16, 0, // bits per sample
-		'd', 'a', 't', 'a',
-		byte(dataLen), byte(dataLen >> 8), byte(dataLen >> 16), byte(dataLen >> 24),
-	}
-
-	if _, err := f.Write(header); err != nil {
-		return err
+	if c.Output != "-" || c.Play {
+		sink, err := openSink(cfg)
+		if err != nil {
+			return nil, err
+		}
+		out.sink = sink
+		c.keys.play(sink, nil)
+		showProgress(sink)
+	}
+	if c.Output == "-" && c.outFormat.StreamHeader != nil && (c.Rate == 0 || c.Rate == audio.SampleRate) {
+		out.stdout = os.Stdout
+		_, out.err = os.Stdout.Write(c.outFormat.StreamHeader(audio.SampleRate))
+	}
+	return out, nil
+}
+
+func (o *sayOutput) Write(pcm []byte) {
+	if o.norm != nil {
+		o.norm.Write(pcm)
+		return
+	}
+	o.write(pcm)
+}
+
+func (o *sayOutput) write(pcm []byte) {
+	if o.sink != nil {
+		o.sink.Write(pcm)
+	}
+	if o.stdout != nil && o.err == nil {
+		_, o.err = o.stdout.Write(pcm)
+	}
+	o.collector.Write(pcm)
+}
+
+func (o *sayOutput) Close() {
+	if o.norm != nil {
+		o.norm.Flush()
+	}
+	if o.sink != nil {
+		o.sink.Close()
+		ui.ClearStatus()
+	}
+}
+
+// readCachedAudio looks up synthesized audio by cache hash and returns it
+// with the name of the file it came from. Entries from older versions
+// (.opus and raw .pcm) are still read.
+func readCachedAudio(cfg *config.AppConfig, hashStr string) ([]byte, string, bool) {
+	dir := filepath.Join(cfg.Dir, "cache")
+	for _, ext := range []string{".ogg", ".opus"} {
+		data, err := os.ReadFile(filepath.Join(dir, hashStr+ext))
+		if err != nil {
+			continue
+		}
+		if pcm, err := audio.DecodeOpusToPCM(data); err == nil {
+			return pcm, hashStr + ext, true
+		}
+	}
+	if pcm, err := os.ReadFile(filepath.Join(dir, hashStr+".pcm")); err == nil {
+		return pcm, hashStr + ".pcm", true
+	}
+	return nil, "", false
+}
+
+// readTextChunks reads r until EOF, passing along text as soon as it arrives.
+// A UTF-8 sequence split across reads is held back until it is complete.
+func readTextChunks(r io.Reader, emit func(string)) error {
+	buf := make([]byte, 4096)
+	var carry []byte
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			data := append(carry, buf[:n]...)
+			cut := len(data)
+			// Back up over an incomplete trailing rune (at most 3 bytes)
+			for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+				if utf8.RuneStart(data[i]) {
+					if !utf8.FullRune(data[i:]) {
+						cut = i
+					}
+					break
+				}
+			}
+			if cut > 0 {
+				emit(string(data[:cut]))
+			}
+			carry = append([]byte(nil), data[cut:]...)
+		}
+		if err == io.EOF {
+			if len(carry) > 0 {
+				emit(string(carry))
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
 	}
-	_, err = f.Write(pcm)
-	return err
 }
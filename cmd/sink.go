@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"sync"
+
+	"github.com/ontypehq/vox/internal/audio"
+	"github.com/ontypehq/vox/internal/config"
+	"github.com/ontypehq/vox/internal/ui"
+)
+
+// sinkAuto is the default sink: the output device with headless fallbacks
+const sinkAuto = "auto"
+
+var fallbackOnce sync.Once
+
+// playsOnDevice reports whether audio goes to the sound card, so the output
+// device setting matters
+func playsOnDevice(cfg *config.AppConfig) bool {
+	spec := cfg.Sink()
+	return spec == "" || spec == sinkAuto || spec == audio.SinkDevice
+}
+
+// openSink opens where audio plays. In auto mode (the default) that is the
+// output device, falling back to an external player and then to silence
+// when there is no sound hardware, so say and listen still run headless.
+func openSink(cfg *config.AppConfig) (audio.Sink, error) {
+	if spec := cfg.Sink(); spec != "" && spec != sinkAuto {
+		return audio.OpenSink(spec, cfg.OutputDevice())
+	}
+
+	player, err := audio.NewStreamPlayer(cfg.OutputDevice())
+	if err == nil {
+		return player, nil
+	}
+	if cfg.OutputDevice() != "" {
+		// A device was asked for by name; don't hide that it's missing
+		return nil, err
+	}
+
+	if command := audio.FallbackPlayer(); command != "" {
+		if sink, cerr := audio.NewCommandSink(command); cerr == nil {
+			fallbackOnce.Do(func() { ui.Warn("%v — playing through %s", err, command) })
+			return sink, nil
+		}
+	}
+	fallbackOnce.Do(func() { ui.Warn("%v — audio will not be played (set --sink to choose)", err) })
+	return audio.NullSink{}, nil
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ontypehq/vox/internal/provider"
+)
+
+// segmentTimeout bounds the synthesis of a single segment. Audio is
+// buffered rather than played inline, so this covers synthesis time only.
+const segmentTimeout = 90 * time.Second
+
+// segmentAudio collects one segment's audio while it is synthesized
+type segmentAudio struct {
+	mu     sync.Mutex
+	chunks [][]byte
+	done   bool
+	err    error
+	notify chan struct{}
+}
+
+func (s *segmentAudio) add(pcm []byte) {
+	s.mu.Lock()
+	s.chunks = append(s.chunks, pcm)
+	s.mu.Unlock()
+	s.signal()
+}
+
+func (s *segmentAudio) finish(err error) {
+	s.mu.Lock()
+	s.done = true
+	s.err = err
+	s.mu.Unlock()
+	s.signal()
+}
+
+func (s *segmentAudio) signal() {
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// take returns the chunks received since the last call
+func (s *segmentAudio) take() (chunks [][]byte, done bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	chunks, s.chunks = s.chunks, nil
+	return chunks, s.done, s.err
+}
+
+// synthesizeSegments synthesizes segments with up to parallel of them in
+// flight and delivers their audio to onAudio strictly in order, so playback
+// is gapless. The first segment streams as it arrives; later ones buffer
+// until their turn. A segment only starts once the one parallel places
+// earlier has been fully delivered, which bounds memory.
+func synthesizeSegments(ctx context.Context, p provider.Synthesizer, opts provider.TTSOptions, segments []string, parallel int, onAudio func([]byte)) error {
+	if parallel < 1 {
+		parallel = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	audio := make([]*segmentAudio, len(segments))
+	for i := range audio {
+		audio[i] = &segmentAudio{notify: make(chan struct{}, 1)}
+	}
+
+	slots := make(chan struct{}, parallel)
+	go func() {
+		for i, text := range segments {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				for _, a := range audio[i:] {
+					a.finish(ctx.Err())
+				}
+				return
+			}
+			go func() {
+				segOpts := opts
+				segOpts.Text = text
+				segCtx, segCancel := context.WithTimeout(ctx, segmentTimeout)
+				defer segCancel()
+				audio[i].finish(p.StreamTTS(segCtx, segOpts, audio[i].add))
+			}()
+		}
+	}()
+
+	for i, a := range audio {
+		for {
+			chunks, done, err := a.take()
+			for _, pcm := range chunks {
+				onAudio(pcm)
+			}
+			if done {
+				if err != nil && len(segments) > 1 {
+					return fmt.Errorf("segment %d/%d: %w", i+1, len(segments), err)
+				}
+				if err != nil {
+					return err
+				}
+				break
+			}
+			<-a.notify
+		}
+		<-slots
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ontypehq/vox/internal/audio"
+	"github.com/ontypehq/vox/internal/audio/wav"
+	"github.com/ontypehq/vox/internal/provider"
+	"github.com/ontypehq/vox/internal/segment"
+	"github.com/ontypehq/vox/internal/ui"
+)
+
+const (
+	// maxChunkDuration keeps each ASR request well under the API's
+	// per-request audio length limit
+	maxChunkDuration = 2 * time.Minute
+	// maxChunkBytes keeps the base64 request body under the size limit
+	maxChunkBytes = 5 << 20
+)
+
+// chunkLimit returns the longest chunk that fits both request limits
+func chunkLimit(sampleRate int) time.Duration {
+	limit := maxChunkDuration
+	if bySize := time.Duration(maxChunkBytes/(sampleRate*2)) * time.Second; bySize < limit {
+		limit = bySize
+	}
+	return limit
+}
+
+// transcribeFunc sends one WAV file for transcription
+type transcribeFunc func(wavData []byte, hint string) (*provider.Transcript, error)
+
+// transcribeChunks transcribes 16-bit mono PCM chunks with up to parallel
+// requests in flight and stitches the results back in order. Each chunk
+// becomes one segment unless the provider returned finer timing for it.
+// With skipFailed, chunks that fail are left out with a warning, and only
+// all of them failing is an error. onDone is called (from any goroutine)
+// as each chunk finishes.
+func transcribeChunks(transcribe transcribeFunc, chunks []audio.Chunk, sampleRate int, hint string, parallel int, skipFailed bool, onDone func(done, total int)) (*provider.Transcript, error) {
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	results := make([]*provider.Transcript, len(chunks))
+	errs := make([]error, len(chunks))
+	slots := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	done := 0
+
+	for i, chunk := range chunks {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			result, err := transcribe(wav.Encode(chunk.PCM, sampleRate), hint)
+			if err != nil {
+				errs[i] = fmt.Errorf("chunk %d/%d at %s: %w", i+1, len(chunks), formatOffset(chunk.Start), err)
+			}
+			results[i] = result
+
+			mu.Lock()
+			done++
+			if onDone != nil {
+				onDone(done, len(chunks))
+			}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	var segments []provider.TranscriptSegment
+	var texts []string
+	var failed []error
+	for i, err := range errs {
+		if err != nil {
+			if !skipFailed {
+				return nil, err
+			}
+			failed = append(failed, err)
+			continue
+		}
+		chunk, result := chunks[i], results[i]
+		texts = append(texts, result.Text)
+		if len(result.Segments) == 0 {
+			end := chunk.Start + time.Duration(len(chunk.PCM)/2)*time.Second/time.Duration(sampleRate)
+			segments = append(segments, provider.TranscriptSegment{Start: chunk.Start, End: end, Text: result.Text})
+			continue
+		}
+		for _, seg := range result.Segments {
+			seg.Start += chunk.Start
+			seg.End += chunk.Start
+			segments = append(segments, seg)
+		}
+	}
+	switch {
+	case len(failed) == len(chunks) && len(chunks) > 0:
+		return nil, failed[0]
+	case len(failed) > 0:
+		ui.Warn("%d of %d chunks failed and are missing from the transcript; first: %v", len(failed), len(chunks), failed[0])
+	}
+	return &provider.Transcript{Text: segment.Join(texts), Segments: segments}, nil
+}
+
+// formatOffset renders an offset into a recording as h:mm:ss
+func formatOffset(d time.Duration) string {
+	s := int(d.Round(time.Second).Seconds())
+	return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+}
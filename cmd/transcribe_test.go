@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ontypehq/vox/internal/audio"
+	"github.com/ontypehq/vox/internal/audio/wav"
+	"github.com/ontypehq/vox/internal/provider"
+)
+
+func TestTranscribeChunksTiming(t *testing.T) {
+	const rate = 16000
+	chunks := []audio.Chunk{
+		{Start: 0, PCM: make([]byte, rate*2)},                  // 1s, untimed
+		{Start: 5 * time.Second, PCM: make([]byte, rate*2*3)},  // 3s, timed by the engine
+		{Start: 20 * time.Second, PCM: make([]byte, rate*2/2)}, // 0.5s, untimed
+	}
+	transcribe := func(wavData []byte, hint string) (*provider.Transcript, error) {
+		a, err := wav.Decode(wavData)
+		if err != nil {
+			return nil, err
+		}
+		if len(a.Data) == rate*2*3 {
+			return &provider.Transcript{Text: "b c", Segments: []provider.TranscriptSegment{
+				{Start: 0, End: time.Second, Text: "b"},
+				{Start: 2 * time.Second, End: 3 * time.Second, Text: "c"},
+			}}, nil
+		}
+		return &provider.Transcript{Text: map[int]string{rate * 2: "a", rate: "d"}[len(a.Data)]}, nil
+	}
+
+	got, err := transcribeChunks(transcribe, chunks, rate, "", 2, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []provider.TranscriptSegment{
+		{Start: 0, End: time.Second, Text: "a"},
+		{Start: 5 * time.Second, End: 6 * time.Second, Text: "b"},
+		{Start: 7 * time.Second, End: 8 * time.Second, Text: "c"},
+		{Start: 20 * time.Second, End: 20*time.Second + 500*time.Millisecond, Text: "d"},
+	}
+	if !slices.Equal(got.Segments, want) {
+		t.Errorf("segments = %v, want %v", got.Segments, want)
+	}
+	if got.Text != "a b c d" {
+		t.Errorf("text = %q", got.Text)
+	}
+}
+
+func TestTranscribeChunksFailures(t *testing.T) {
+	chunks := []audio.Chunk{
+		{Start: 0, PCM: make([]byte, 320)},
+		{Start: time.Second, PCM: make([]byte, 640)},
+	}
+	failSecond := func(wavData []byte, hint string) (*provider.Transcript, error) {
+		if len(wavData) > 44+320 {
+			return nil, errors.New("throttled")
+		}
+		return &provider.Transcript{Text: "first"}, nil
+	}
+	failAll := func(wavData []byte, hint string) (*provider.Transcript, error) {
+		return nil, errors.New("throttled")
+	}
+
+	if _, err := transcribeChunks(failSecond, chunks, 16000, "", 1, false, nil); err == nil {
+		t.Error("a failed chunk didn't fail the transcript")
+	}
+	got, err := transcribeChunks(failSecond, chunks, 16000, "", 1, true, nil)
+	if err != nil {
+		t.Fatalf("skipping failed chunks: %v", err)
+	}
+	if got.Text != "first" || len(got.Segments) != 1 {
+		t.Errorf("got %q with %d segments, want only the first chunk", got.Text, len(got.Segments))
+	}
+	if _, err := transcribeChunks(failAll, chunks, 16000, "", 1, true, nil); err == nil {
+		t.Error("no error when every chunk failed")
+	}
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ontypehq/vox/internal/audio/wav"
+	"github.com/ontypehq/vox/internal/ui"
+)
+
+// prepareUpload converts a user-supplied WAV file to the 16-bit mono PCM at
+// sampleRate that the API expects, so 44.1kHz stereo phone recordings and
+// 24-bit or float exports work as-is. Other containers (mp3, m4a, ...) are
+// passed through for the API to decode.
+func prepareUpload(data []byte, sampleRate int) ([]byte, error) {
+	out, format, err := wav.Normalize(data, sampleRate)
+	if errors.Is(err, wav.ErrNotWAV) {
+		return data, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !format.Is(sampleRate) {
+		ui.Info("%s %s", ui.Dim("converted"), ui.Dim(fmt.Sprintf("%s → %d Hz mono 16-bit", format, sampleRate)))
+	}
+	return out, nil
+}
@@ -1,8 +1,9 @@
 package cmd
 
 import (
-	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -10,38 +11,62 @@ import (
 	"time"
 
 	"github.com/ontypehq/vox/internal/audio"
+	"github.com/ontypehq/vox/internal/audio/wav"
 	"github.com/ontypehq/vox/internal/config"
-	"github.com/ontypehq/vox/internal/dashscope"
+	"github.com/ontypehq/vox/internal/provider"
 	"github.com/ontypehq/vox/internal/ui"
 )
 
 type VoiceCmd struct {
 	List   VoiceListCmd   `cmd:"" help:"List available voices"`
 	Record VoiceRecordCmd `cmd:"" help:"Record and enroll a voice clone"`
+	Check  VoiceCheckCmd  `cmd:"" help:"Check a WAV file's suitability as a voice clone sample"`
 	Delete VoiceDeleteCmd `cmd:"" help:"Delete a cloned voice"`
 }
 
 // --- voice list ---
 
-type VoiceListCmd struct{}
+type VoiceListCmd struct {
+	JSON bool `help:"Print cloned voices as JSON to stdout"`
+}
 
 func (c *VoiceListCmd) Run(cfg *config.AppConfig) error {
+	p, err := provider.Lookup(cfg)
+	if err != nil {
+		return err
+	}
+	defer p.Close()
+
+	if c.JSON {
+		if err := p.Ready(); err != nil {
+			return err
+		}
+		voices, err := p.ListVoices()
+		if err != nil {
+			return err
+		}
+		if voices == nil {
+			voices = []provider.Voice{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(voices)
+	}
+
 	// System voices (always available)
 	ui.Info("\n%s", ui.Key("System Voices"))
 	ui.Info("%s", ui.Dim("  (use with: vox say --voice <name> \"text\")"))
-	for _, v := range dashscope.SystemVoices {
+	for _, v := range p.SystemVoices() {
 		ui.Info("  %-12s %s  %s", ui.Key(v.ID), ui.Dim(v.Gender), ui.Dim(v.Language))
 	}
 
 	// Cloned voices (from API)
-	apiKey, err := cfg.RequireAPIKey()
-	if err != nil {
+	if err := p.Ready(); err != nil {
 		ui.Info("\n%s", ui.Dim("  (login to see cloned voices)"))
 		return nil
 	}
 
-	client := dashscope.NewClient(apiKey)
-	voices, err := client.ListVoices(0, 50)
+	voices, err := p.ListVoices()
 	if err != nil {
 		ui.Warn("Failed to fetch cloned voices: %v", err)
 		return nil
@@ -52,14 +77,13 @@ func (c *VoiceListCmd) Run(cfg *config.AppConfig) error {
 		return nil
 	}
 
-	ui.Info("\n%s", ui.Key("Cloned Voices"))
+	ui.Info("\n%s %s", ui.Key("Cloned Voices"), ui.Dim(fmt.Sprintf("(%d)", len(voices))))
 	for _, v := range voices {
-		voiceID, _ := v["voice"].(string)
-		lang, _ := v["language"].(string)
-		model, _ := v["target_model"].(string)
-		// Extract name from voice ID: "qwen-tts-vc-<name>-voice-..."
-		name := extractNameFromVoiceID(voiceID)
-		ui.Info("  %-12s %s  %s  %s", ui.Key(name), ui.Dim(voiceID), ui.Dim(lang), ui.Dim(model))
+		created := ""
+		if !v.CreatedAt.IsZero() {
+			created = v.CreatedAt.Local().Format("2006-01-02")
+		}
+		ui.Info("  %-12s %s  %s  %s  %s", ui.Key(v.Name), ui.Dim(v.ID), ui.Dim(v.Language), ui.Dim(v.Model), ui.Dim(created))
 	}
 
 	return nil
@@ -90,15 +114,18 @@ var sampleTexts = map[string]string{
 type VoiceRecordCmd struct {
 	Lang     string `short:"l" help:"Language for sample text (zh, en, ja). Auto-detected from system if omitted."`
 	Name     string `short:"n" help:"Name for the cloned voice"`
-	Duration int    `short:"d" default:"15" help:"Recording duration in seconds (10-20s recommended)"`
+	Duration int    `short:"d" default:"15" help:"Maximum recording length in seconds; recording stops after trailing silence (10-20s recommended)"`
 	File     string `short:"f" help:"Use existing audio file instead of recording"`
+	Force    bool   `help:"Enroll even if the sample fails the quality checks"`
+	Clean    bool   `default:"true" negatable:"" help:"Clean up the sample: high-pass, noise reduction, silence trimming, normalization"`
 }
 
 func (c *VoiceRecordCmd) Run(cfg *config.AppConfig) error {
-	apiKey, err := cfg.RequireAPIKey()
+	p, err := provider.Open(cfg)
 	if err != nil {
 		return err
 	}
+	defer p.Close()
 
 	// Resolve language
 	lang := c.Lang
@@ -116,6 +143,32 @@ func (c *VoiceRecordCmd) Run(cfg *config.AppConfig) error {
 			return fmt.Errorf("read file: %w", err)
 		}
 		ui.Info("Using audio file: %s", ui.Key(c.File))
+		orig, origErr := wav.Decode(audioData)
+		if audioData, err = prepareUpload(audioData, audio.SampleRate); err != nil {
+			return err
+		}
+		if a, err := wav.Decode(audioData); err == nil {
+			// Check the file as it is; cleaning would hide clipping, DC
+			// offset and noise. Report the source's format when it was a WAV.
+			format := a.Format
+			if origErr == nil {
+				format = orig.Format
+			}
+			pcm := a.Mono16()
+			if err := checkSample(pcm, a.Format.SampleRate, format, c.Force); err != nil {
+				return err
+			}
+			if c.Clean {
+				pcm = cleanRecording(pcm, a.Format.SampleRate)
+			}
+			speech, err := speechOnly(pcm, a.Format.SampleRate)
+			if err != nil {
+				return err
+			}
+			audioData = wav.Encode(speech, a.Format.SampleRate)
+		} else if origErr != nil {
+			ui.Info("%s", ui.Dim("not a WAV file, skipping the sample check"))
+		}
 	} else {
 		// Record from microphone
 		sample, ok := sampleTexts[lang]
@@ -125,22 +178,42 @@ func (c *VoiceRecordCmd) Run(cfg *config.AppConfig) error {
 
 		ui.Info("\n%s", ui.Key("Read this aloud:"))
 		ui.Info("  %s\n", sample)
-		ui.Info("Recording for %ds... %s", c.Duration, ui.Dim("(speak now)"))
+		ui.Info("Recording up to %ds... %s", c.Duration, ui.Dim("(speak now, stops when you finish)"))
 
-		recorder, err := audio.NewRecorder(audio.SampleRate, 1)
+		recorder, err := audio.NewRecorder(audio.SampleRate, 1, cfg.InputDevice())
 		if err != nil {
 			return fmt.Errorf("init recorder: %w", err)
 		}
+		limit := time.Duration(c.Duration) * time.Second
+		vad := audio.NewVAD(audio.SampleRate, audio.VADConfig{Hangover: enrollHangover, MaxLength: limit})
+		recorder.OnData(vad.Write)
+		meter := meterRecorder(recorder, limit)
 
-		recorder.Start()
-		time.Sleep(time.Duration(c.Duration) * time.Second)
-		audioData = recorder.Stop()
+		if err := recorder.Start(); err != nil {
+			return fmt.Errorf("start recording: %w", err)
+		}
+		stop, cancel := speechContext(vad, limit)
+		<-stop.Done()
+		cancel()
+		pcm := recorder.Stop()
+		meter.finish()
+		format := wav.Format{SampleRate: audio.SampleRate, Channels: 1, BitsPerSample: 16}
+		if err := checkSample(pcm, audio.SampleRate, format, c.Force); err != nil {
+			return err
+		}
+		if c.Clean {
+			pcm = cleanRecording(pcm, audio.SampleRate)
+		}
+		audioData, err = speechOnly(pcm, audio.SampleRate)
+		if err != nil {
+			return err
+		}
 
 		ui.Success("Recorded %d bytes", len(audioData))
 
 		// Save locally
 		wavPath := filepath.Join(cfg.Dir, "voices", fmt.Sprintf("recording-%d.wav", time.Now().Unix()))
-		if err := writeWAV(wavPath, audioData); err != nil {
+		if err := wav.WriteFile(wavPath, audioData, audio.SampleRate); err != nil {
 			ui.Warn("Failed to save local copy: %v", err)
 		}
 	}
@@ -156,63 +229,130 @@ func (c *VoiceRecordCmd) Run(cfg *config.AppConfig) error {
 	if c.File != "" {
 		wavData = audioData
 	} else {
-		wavData = wrapPCMAsWAV(audioData)
+		wavData = wav.Encode(audioData, audio.SampleRate)
 	}
 
 	// Enroll voice
 	ui.Info("Enrolling voice %s...", ui.Key(name))
-	client := dashscope.NewClient(apiKey)
-	voiceID, err := client.EnrollVoice(name, base64.StdEncoding.EncodeToString(wavData))
+	voice, err := p.EnrollVoice(name, wavData)
 	if err != nil {
 		return fmt.Errorf("enroll: %w", err)
 	}
 
 	ui.Success("Voice enrolled!")
-	ui.KV("Voice ID", voiceID)
-	ui.KV("Name", name)
-	ui.Info("\n  Use it: %s", ui.Key(fmt.Sprintf("vox say --voice %s \"Hello!\"", voiceID)))
+	ui.KV("Voice ID", voice.ID)
+	ui.KV("Name", voice.Name)
+	ui.KV("Model", voice.Model)
+	ui.Info("\n  Use it: %s", ui.Key(fmt.Sprintf("vox say --voice %s \"Hello!\"", voice.ID)))
 
 	// Save as last voice
-	cfg.State.LastVoice = voiceID
+	cfg.State.LastVoice = voice.ID
 	cfg.SaveState()
 
 	return nil
 }
 
-func writeWAV(path string, pcm []byte) error {
-	f, err := os.Create(path)
-	if err != nil {
-		return err
+const (
+	// enrollHangover is the pause that ends a sample recording; readers
+	// stop between sentences, so it is longer than for dictation
+	enrollHangover = 2 * time.Second
+	// enrollPause is the longest pause kept in an enrollment sample
+	enrollPause = 500 * time.Millisecond
+)
+
+// cleanRecording runs the audio.Clean chain on a recording
+func cleanRecording(pcm []byte, sampleRate int) []byte {
+	cleaned := audio.Clean(pcm, sampleRate)
+	secs := func(b []byte) float64 { return float64(len(b)) / float64(sampleRate*2) }
+	ui.Info("%s %s", ui.Dim("cleaned"), ui.Dim(fmt.Sprintf("%.1fs → %.1fs", secs(pcm), secs(cleaned))))
+	return cleaned
+}
+
+// speechOnly strips leading and trailing silence from an enrollment sample
+// and shortens long pauses, so the clone is built from the voice rather
+// than room noise
+func speechOnly(pcm []byte, sampleRate int) ([]byte, error) {
+	speech := audio.KeepSpeech(pcm, sampleRate, enrollPause)
+	if len(speech) == 0 {
+		return nil, fmt.Errorf("no speech detected in the sample")
+	}
+	if removed := len(pcm) - len(speech); removed > 0 {
+		secs := float64(removed) / float64(sampleRate*2)
+		ui.Info("%s %s", ui.Dim("trimmed"), ui.Dim(fmt.Sprintf("%.1fs of silence", secs)))
 	}
-	defer f.Close()
-	wav := wrapPCMAsWAV(pcm)
-	_, err = f.Write(wav)
-	return err
+	return speech, nil
 }
 
-func wrapPCMAsWAV(pcm []byte) []byte {
-	dataLen := uint32(len(pcm))
-	fileLen := dataLen + 36
-	sr := uint32(audio.SampleRate)
-	br := sr * 2
-
-	header := []byte{
-		'R', 'I', 'F', 'F',
-		byte(fileLen), byte(fileLen >> 8), byte(fileLen >> 16), byte(fileLen >> 24),
-		'W', 'A', 'V', 'E',
-		'f', 'm', 't', ' ',
-		16, 0, 0, 0,
-		1, 0, // PCM
-		1, 0, // mono
-		byte(sr), byte(sr >> 8), byte(sr >> 16), byte(sr >> 24),
-		byte(br), byte(br >> 8), byte(br >> 16), byte(br >> 24),
-		2, 0,  // block align
-		16, 0, // bits per sample
-		'd', 'a', 't', 'a',
-		byte(dataLen), byte(dataLen >> 8), byte(dataLen >> 16), byte(dataLen >> 24),
+// checkSample analyzes a recording as 16-bit mono PCM before enrollment and
+// refuses a sample that would make a poor clone, unless forced. It takes
+// the recording as captured, before cleaning or cutting pauses, so the
+// silence ratio, noise floor, clipping and DC offset are still measurable.
+// format is the source's, since an upsampled 8 kHz file is still an 8 kHz
+// sample.
+func checkSample(pcm []byte, sampleRate int, format wav.Format, force bool) error {
+	report := audio.AnalyzeSample(&wav.Audio{Format: wav.Format{SampleRate: sampleRate, Channels: 1, BitsPerSample: 16}, Data: pcm})
+	report.Format = format
+	issues := report.Issues()
+	printSampleReport(report, issues)
+	if audio.Fatal(issues) {
+		if !force {
+			return fmt.Errorf("sample failed the quality check (use --force to enroll anyway)")
+		}
+		ui.Warn("Enrolling anyway (--force)")
 	}
+	return nil
+}
 
-	return append(header, pcm...)
+func printSampleReport(r audio.SampleReport, issues []audio.SampleIssue) {
+	ui.Info("\n%s", ui.Key("Sample Check"))
+	ui.KV("Format", r.Format.String())
+	ui.KV("Length", fmt.Sprintf("%.1fs, %.1fs speech, %.0f%% silence", r.Duration.Seconds(), r.Speech.Seconds(), r.Silence*100))
+	snr := "n/a"
+	switch {
+	case math.IsInf(r.SNR, 1):
+		snr = "no background noise"
+	case r.Speech > 0:
+		snr = fmt.Sprintf("%.0f dB", r.SNR)
+	}
+	ui.KV("SNR", snr)
+	ui.KV("Peak", fmt.Sprintf("%.1f dBFS, %d clipped samples", audio.DB(r.Peak), r.Clipped))
+	ui.KV("DC offset", fmt.Sprintf("%.2f%%", r.DCOffset*100))
+
+	for _, issue := range issues {
+		if issue.Fatal {
+			ui.Error("%s", issue.Message)
+		} else {
+			ui.Warn("%s", issue.Message)
+		}
+	}
+	if len(issues) == 0 {
+		ui.Success("Sample looks good")
+	}
+}
+
+// --- voice check ---
+
+type VoiceCheckCmd struct {
+	File string `arg:"" type:"existingfile" help:"WAV file to check"`
+}
+
+func (c *VoiceCheckCmd) Run(cfg *config.AppConfig) error {
+	data, err := os.ReadFile(c.File)
+	if err != nil {
+		return fmt.Errorf("read file: %w", err)
+	}
+	a, err := wav.Decode(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", c.File, err)
+	}
+
+	report := audio.AnalyzeSample(a)
+	issues := report.Issues()
+	printSampleReport(report, issues)
+	if audio.Fatal(issues) {
+		return fmt.Errorf("not suitable for voice cloning")
+	}
+	return nil
 }
 
 // --- voice delete ---
@@ -222,17 +362,17 @@ type VoiceDeleteCmd struct {
 }
 
 func (c *VoiceDeleteCmd) Run(cfg *config.AppConfig) error {
-	apiKey, err := cfg.RequireAPIKey()
+	p, err := provider.Open(cfg)
 	if err != nil {
 		return err
 	}
+	defer p.Close()
 
-	if dashscope.IsSystemVoice(c.VoiceID) {
+	if provider.IsSystemVoice(p, c.VoiceID) {
 		return fmt.Errorf("cannot delete system voice: %s", c.VoiceID)
 	}
 
-	client := dashscope.NewClient(apiKey)
-	if err := client.DeleteVoice(c.VoiceID); err != nil {
+	if err := p.DeleteVoice(c.VoiceID); err != nil {
 		return err
 	}
 
@@ -247,23 +387,6 @@ func (c *VoiceDeleteCmd) Run(cfg *config.AppConfig) error {
 	return nil
 }
 
-// extractNameFromVoiceID extracts the user-chosen name from voice ID
-// e.g. "qwen-tts-vc-dio-voice-20260220..." → "dio"
-func extractNameFromVoiceID(id string) string {
-	// Pattern: qwen-tts-vc-<name>-voice-<timestamp>-<hash>
-	const prefix = "qwen-tts-vc-"
-	const marker = "-voice-"
-	if !strings.HasPrefix(id, prefix) {
-		return id
-	}
-	rest := id[len(prefix):]
-	idx := strings.Index(rest, marker)
-	if idx < 0 {
-		return id
-	}
-	return rest[:idx]
-}
-
 // localeToLang maps macOS locale prefixes to language codes
 var localeToLang = map[string]string{
 	"zh": "zh", "en": "en", "ja": "ja", "ko": "ko",
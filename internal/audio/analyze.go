@@ -0,0 +1,168 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/ontypehq/vox/internal/audio/wav"
+)
+
+// Voice clone sample guidelines
+const (
+	idealSpeechMin  = 10 * time.Second // recommended speech, lower bound
+	idealSpeechMax  = 20 * time.Second // recommended speech, upper bound
+	minSampleSpeech = 5 * time.Second  // too little to clone from
+	maxSampleLength = 60 * time.Second // longest upload accepted
+	minSampleRate   = 16000
+	maxSilenceRatio = 0.4
+	minSNR          = 15.0 // dB; noisier samples clone the noise
+	goodSNR         = 25.0
+	maxClipRatio    = 0.001 // share of samples at full scale
+	maxDCOffset     = 0.02
+	sampleClipLevel = 0.999
+	clipWarnSamples = 10
+)
+
+// SampleReport describes how well a recording suits voice cloning
+type SampleReport struct {
+	Format   wav.Format
+	Duration time.Duration
+	Speech   time.Duration // time classified as speech
+	Silence  float64       // share of the recording that is not speech
+	SNR      float64       // speech over background level in dB; +Inf if the background is digital silence
+	Peak     float64       // highest sample (0-1)
+	Clipped  int           // samples at full scale
+	Clip     float64       // share of samples at full scale
+	DCOffset float64       // mean sample value (-1 to 1)
+}
+
+// SampleIssue is a problem found in a sample. Fatal issues make a clone
+// not worth the upload.
+type SampleIssue struct {
+	Message string
+	Fatal   bool
+}
+
+// AnalyzeSample measures a decoded WAV file for voice enrollment
+func AnalyzeSample(a *wav.Audio) SampleReport {
+	samples := a.Mono()
+	r := SampleReport{
+		Format:   a.Format,
+		Duration: time.Duration(len(samples)) * time.Second / time.Duration(a.Format.SampleRate),
+	}
+	if len(samples) == 0 {
+		return r
+	}
+
+	var sum float64
+	for _, s := range samples {
+		v := math.Abs(float64(s))
+		sum += float64(s)
+		r.Peak = max(r.Peak, v)
+		if v >= sampleClipLevel {
+			r.Clipped++
+		}
+	}
+	r.DCOffset = sum / float64(len(samples))
+	r.Clip = float64(r.Clipped) / float64(len(samples))
+
+	pcm := wav.PCM16(samples)
+	speech := speechFrames(pcm, a.Format.SampleRate, 0)
+	rms := FrameRMS(pcm, a.Format.SampleRate)
+	if len(speech) == 0 {
+		return r
+	}
+
+	var speechPower, noisePower float64
+	var speechN, noiseN int
+	for i, s := range speech {
+		p := rms[i] * rms[i]
+		if s {
+			speechPower += p
+			speechN++
+		} else {
+			noisePower += p
+			noiseN++
+		}
+	}
+	r.Speech = time.Duration(speechN) * frameDuration
+	r.Silence = float64(noiseN) / float64(len(speech))
+
+	if speechN == 0 {
+		return r
+	}
+	speechPower /= float64(speechN)
+	if noiseN > 0 {
+		noisePower /= float64(noiseN)
+	} else {
+		// All speech (already trimmed): take the quietest frames as the
+		// background
+		sorted := slices.Sorted(slices.Values(rms))
+		floor := sorted[len(sorted)/10]
+		noisePower = floor * floor
+	}
+	r.SNR = 10 * math.Log10(speechPower/noisePower)
+	return r
+}
+
+// Issues checks the report against the voice clone guidelines
+func (r SampleReport) Issues() []SampleIssue {
+	var issues []SampleIssue
+	add := func(fatal bool, format string, a ...any) {
+		issues = append(issues, SampleIssue{Message: fmt.Sprintf(format, a...), Fatal: fatal})
+	}
+	secs := func(d time.Duration) string { return fmt.Sprintf("%.1fs", d.Seconds()) }
+
+	switch {
+	case r.Speech < minSampleSpeech:
+		add(true, "only %s of speech, %.0f-%.0fs recommended", secs(r.Speech), idealSpeechMin.Seconds(), idealSpeechMax.Seconds())
+	case r.Speech < idealSpeechMin:
+		add(false, "%s of speech is on the short side, %.0f-%.0fs recommended", secs(r.Speech), idealSpeechMin.Seconds(), idealSpeechMax.Seconds())
+	case r.Speech > idealSpeechMax:
+		add(false, "%s of speech is more than needed, %.0f-%.0fs recommended", secs(r.Speech), idealSpeechMin.Seconds(), idealSpeechMax.Seconds())
+	}
+	if r.Duration > maxSampleLength {
+		add(true, "recording is %s long, the limit is %.0fs", secs(r.Duration), maxSampleLength.Seconds())
+	}
+	if r.Silence > maxSilenceRatio {
+		add(false, "%.0f%% of the recording is silence", r.Silence*100)
+	}
+
+	switch {
+	case r.Speech == 0:
+	case r.SNR < minSNR:
+		add(true, "background noise is too loud (SNR %.0f dB, want %.0f+)", r.SNR, goodSNR)
+	case r.SNR < goodSNR:
+		add(false, "some background noise (SNR %.0f dB, want %.0f+)", r.SNR, goodSNR)
+	}
+
+	switch {
+	case r.Clip > maxClipRatio:
+		add(true, "clipping: %d samples at full scale (%.2f%%)", r.Clipped, r.Clip*100)
+	case r.Clipped > clipWarnSamples:
+		add(false, "slight clipping: %d samples at full scale", r.Clipped)
+	}
+	if math.Abs(r.DCOffset) > maxDCOffset {
+		add(false, "DC offset of %.1f%% (a faulty or badly grounded microphone)", r.DCOffset*100)
+	}
+
+	if r.Format.SampleRate < minSampleRate {
+		add(true, "sample rate %d Hz is too low, %d Hz or more needed", r.Format.SampleRate, minSampleRate)
+	}
+	if r.Format.BitsPerSample < 16 {
+		add(false, "%d-bit audio is coarse, 16-bit or more recommended", r.Format.BitsPerSample)
+	}
+	return issues
+}
+
+// Fatal reports whether any issue rules the sample out
+func Fatal(issues []SampleIssue) bool {
+	for _, i := range issues {
+		if i.Fatal {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,230 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+	"math/cmplx"
+	"slices"
+	"time"
+)
+
+// Clean chain settings
+const (
+	// highPassCutoff removes rumble, handling noise and DC below the voice
+	highPassCutoff = 80.0
+	// noiseProfileLength is the lead-in taken as the noise profile; people
+	// start talking a moment after recording starts
+	noiseProfileLength = 300 * time.Millisecond
+	// overSubtraction removes a bit more than the measured noise, which
+	// keeps residual noise from flickering ("musical noise")
+	overSubtraction = 2.0
+	// spectralFloor is the least of each bin that is kept, so speech
+	// masked by noise is dulled rather than cut out
+	spectralFloor = 0.05
+	// gainRelease limits how fast a bin's gain may fall between frames
+	gainRelease = 0.7
+	// cleanRMS and cleanPeak are the normalization targets (-20 dBFS RMS
+	// over the speech, peaks at most -1 dBFS)
+	cleanRMS  = 0.1
+	cleanPeak = 0.891
+)
+
+// Clean prepares a microphone recording of 16-bit mono PCM for ASR or voice
+// enrollment: a high-pass filter, spectral-subtraction noise reduction with
+// the first 300ms as the noise profile, leading and trailing silence
+// trimmed, and gain normalized. Steps that don't apply (no quiet lead-in
+// to profile, no speech to trim to) are skipped.
+func Clean(pcm []byte, sampleRate int) []byte {
+	samples := toFloats(pcm)
+	highPass(samples, sampleRate)
+	denoise(samples, sampleRate)
+	out := fromFloats(samples)
+	if trimmed := TrimSilence(out, sampleRate, VADConfig{}); trimmed != nil {
+		out = trimmed
+	}
+	return normalizeLevel(out, sampleRate)
+}
+
+func toFloats(pcm []byte) []float64 {
+	out := make([]float64, len(pcm)/2)
+	for i := range out {
+		out[i] = float64(int16(binary.LittleEndian.Uint16(pcm[i*2:]))) / 32768
+	}
+	return out
+}
+
+func fromFloats(samples []float64) []byte {
+	out := make([]byte, len(samples)*2)
+	for i, s := range samples {
+		v := max(min(s*32768, math.MaxInt16), math.MinInt16)
+		binary.LittleEndian.PutUint16(out[i*2:], uint16(int16(math.Round(v))))
+	}
+	return out
+}
+
+// highPass runs a second-order Butterworth high-pass over samples in place
+func highPass(samples []float64, sampleRate int) {
+	k := math.Tan(math.Pi * highPassCutoff / float64(sampleRate))
+	const q = math.Sqrt2 / 2
+	a0 := 1 + k/q + k*k
+	f := biquad{
+		b0: 1 / a0, b1: -2 / a0, b2: 1 / a0,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+	for i, s := range samples {
+		samples[i] = f.process(s)
+	}
+}
+
+// denoise subtracts the noise spectrum measured over the lead-in from every
+// frame, in place. Frames are Hann-windowed with 50% overlap, which adds
+// back up to the original signal. Skipped if the lead-in has speech in it.
+func denoise(samples []float64, sampleRate int) {
+	size := 512
+	if sampleRate > 24000 {
+		size = 1024
+	}
+	hop := size / 2
+	lead := int(noiseProfileLength * time.Duration(sampleRate) / time.Second)
+	if len(samples) < lead+size || !quietLeadIn(samples, lead, sampleRate) {
+		return
+	}
+
+	window := make([]float64, size)
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(size)) // periodic Hann
+	}
+	frame := func(at int) []complex128 {
+		buf := make([]complex128, size)
+		for i := range size {
+			if j := at + i; j >= 0 && j < len(samples) {
+				buf[i] = complex(samples[j]*window[i], 0)
+			}
+		}
+		fft(buf, false)
+		return buf
+	}
+
+	// Average magnitude spectrum of the lead-in
+	noise := make([]float64, size)
+	var profiles int
+	for at := 0; at+size <= lead; at += hop {
+		for i, v := range frame(at) {
+			noise[i] += cmplx.Abs(v)
+		}
+		profiles++
+	}
+	if profiles == 0 {
+		return
+	}
+	for i := range noise {
+		noise[i] /= float64(profiles)
+	}
+
+	// Starting a hop early gives every sample two overlapping frames
+	out := make([]float64, len(samples))
+	gains := make([]float64, size)
+	for at := -hop; at < len(samples); at += hop {
+		spec := frame(at)
+		for i, v := range spec {
+			mag := cmplx.Abs(v)
+			g := 1.0
+			if mag > 0 {
+				g = max(1-overSubtraction*noise[i]/mag, spectralFloor)
+			}
+			if at > -hop {
+				g = max(g, gains[i]*gainRelease)
+			}
+			gains[i] = g
+			spec[i] = v * complex(g, 0)
+		}
+		fft(spec, true)
+		for i, v := range spec {
+			if j := at + i; j >= 0 && j < len(out) {
+				out[j] += real(v)
+			}
+		}
+	}
+	copy(samples, out)
+}
+
+// quietLeadIn reports whether the first lead samples are background only:
+// every 20ms frame at least 6 dB below the loud parts of the recording
+func quietLeadIn(samples []float64, lead, sampleRate int) bool {
+	rms := FrameRMS(fromFloats(samples), sampleRate)
+	leadFrames := lead * len(rms) / len(samples)
+	if leadFrames == 0 || leadFrames >= len(rms) {
+		return false
+	}
+	loud := slices.Sorted(slices.Values(rms))[len(rms)*9/10]
+	return slices.Max(rms[:leadFrames]) < loud/2
+}
+
+// fft is an in-place radix-2 FFT; len(x) must be a power of two. The
+// inverse transform is scaled by 1/n.
+func fft(x []complex128, inverse bool) {
+	n := len(x)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+	sign := -1.0
+	if inverse {
+		sign = 1
+	}
+	for length := 2; length <= n; length <<= 1 {
+		step := cmplx.Rect(1, sign*2*math.Pi/float64(length))
+		for start := 0; start < n; start += length {
+			w := complex(1, 0)
+			for k := range length / 2 {
+				u, v := x[start+k], x[start+k+length/2]*w
+				x[start+k], x[start+k+length/2] = u+v, u-v
+				w *= step
+			}
+		}
+	}
+	if inverse {
+		for i := range x {
+			x[i] /= complex(float64(n), 0)
+		}
+	}
+}
+
+// normalizeLevel scales 16-bit mono PCM so its speech sits at -20 dBFS RMS,
+// without letting peaks past -1 dBFS
+func normalizeLevel(pcm []byte, sampleRate int) []byte {
+	samples := toFloats(pcm)
+	var peak float64
+	for _, s := range samples {
+		peak = max(peak, math.Abs(s))
+	}
+	if peak == 0 {
+		return pcm
+	}
+
+	// RMS over the speech frames only, so pauses don't drag it down
+	var power float64
+	var n int
+	rms := FrameRMS(pcm, sampleRate)
+	for i, speech := range speechFrames(pcm, sampleRate, 0) {
+		if speech {
+			power += rms[i] * rms[i]
+			n++
+		}
+	}
+	gain := cleanPeak / peak
+	if n > 0 {
+		gain = min(cleanRMS/math.Sqrt(power/float64(n)), gain)
+	}
+	for i := range samples {
+		samples[i] *= gain
+	}
+	return fromFloats(samples)
+}
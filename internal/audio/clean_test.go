@@ -0,0 +1,70 @@
+package audio
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand/v2"
+	"testing"
+	"time"
+)
+
+func TestFFTRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 4))
+	for _, n := range []int{1, 2, 8, 512, 1024} {
+		x := make([]complex128, n)
+		for i := range x {
+			x[i] = complex(r.Float64()*2-1, r.Float64()*2-1)
+		}
+		y := append([]complex128(nil), x...)
+		fft(y, false)
+		fft(y, true)
+		for i := range x {
+			if cmplx.Abs(y[i]-x[i]) > 1e-9 {
+				t.Fatalf("n=%d: sample %d came back as %v, want %v", n, i, y[i], x[i])
+			}
+		}
+	}
+}
+
+func TestFFTMatchesDFT(t *testing.T) {
+	const n = 16
+	r := rand.New(rand.NewPCG(5, 6))
+	x := make([]complex128, n)
+	for i := range x {
+		x[i] = complex(r.Float64(), 0)
+	}
+	y := append([]complex128(nil), x...)
+	fft(y, false)
+	for k := range n {
+		var want complex128
+		for i, v := range x {
+			want += v * cmplx.Rect(1, -2*math.Pi*float64(k*i)/n)
+		}
+		if cmplx.Abs(y[k]-want) > 1e-9 {
+			t.Errorf("bin %d = %v, want %v", k, y[k], want)
+		}
+	}
+}
+
+func TestCleanWithoutLeadIn(t *testing.T) {
+	// Speech from the first sample: nothing to take a noise profile from
+	// and no silence to trim, so only the level changes
+	const rate = 16000
+	in := tone(1000, 0.5, 2*time.Second, rate)
+	out := Clean(in, rate)
+	if len(out) != len(in) {
+		t.Fatalf("got %s back from %s", pcmDuration(out), pcmDuration(in))
+	}
+
+	inRMS, outRMS := FrameRMS(in, rate), FrameRMS(out, rate)
+	gain := outRMS[len(outRMS)/2] / inRMS[len(inRMS)/2]
+	// Skip the first frames, where the high-pass filter settles
+	for f := 3; f < len(outRMS); f++ {
+		if g := outRMS[f] / inRMS[f]; math.Abs(g-gain) > 0.01*gain {
+			t.Fatalf("frame %d has gain %.4f, the rest %.4f", f, g, gain)
+		}
+	}
+	if db := 20 * math.Log10(outRMS[len(outRMS)/2]); math.Abs(db-(-20)) > 0.5 {
+		t.Errorf("cleaned to %.1f dBFS RMS, want -20", db)
+	}
+}
@@ -2,34 +2,46 @@ package audio
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"os/exec"
+
+	"github.com/ontypehq/vox/internal/audio/flac"
 )
 
-// EncodePCMToOpus encodes raw PCM (24kHz 16-bit mono) to Opus via ffmpeg.
+// EncodePCMToOpus compresses raw PCM (24kHz 16-bit mono) for the TTS cache.
+// When ffmpeg is installed it encodes Ogg Opus at 24 kbps, several times
+// smaller than FLAC. Without ffmpeg, or if it fails, it produces Ogg FLAC
+// in-process, so caching never depends on ffmpeg. Both are Ogg files.
 func EncodePCMToOpus(pcm []byte) ([]byte, error) {
-	cmd := exec.Command("ffmpeg",
-		"-f", "s16le",
-		"-ar", fmt.Sprintf("%d", SampleRate),
-		"-ac", fmt.Sprintf("%d", ChannelCount),
-		"-i", "pipe:0",
-		"-c:a", "libopus",
-		"-b:a", "24k",
-		"-f", "opus",
-		"pipe:1",
-	)
-	cmd.Stdin = bytes.NewReader(pcm)
-	var out bytes.Buffer
-	cmd.Stdout = &out
+	if _, err := exec.LookPath("ffmpeg"); err == nil {
+		if opus, err := ffmpegEncode(pcm, SampleRate, "-c:a", "libopus", "-b:a", "24k", "-f", "opus"); err == nil {
+			return opus, nil
+		}
+	}
+	return flac.EncodeOgg(pcm, SampleRate), nil
+}
 
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("ffmpeg encode: %w", err)
+// DecodeOpusToPCM decodes a cache entry back to raw PCM (24kHz 16-bit mono).
+// Ogg FLAC is decoded in-process; Opus, written when ffmpeg is installed or
+// by older versions, is handed to ffmpeg.
+func DecodeOpusToPCM(data []byte) ([]byte, error) {
+	pcm, rate, err := flac.DecodeOgg(data)
+	if errors.Is(err, flac.ErrNotFLAC) {
+		return ffmpegDecode(data)
 	}
-	return out.Bytes(), nil
+	if err != nil {
+		return nil, err
+	}
+	if rate != SampleRate {
+		return nil, fmt.Errorf("cache entry is %d Hz, expected %d Hz", rate, SampleRate)
+	}
+	return pcm, nil
 }
 
-// DecodeOpusToPCM decodes Opus back to raw PCM (24kHz 16-bit mono) via ffmpeg.
-func DecodeOpusToPCM(opus []byte) ([]byte, error) {
+// ffmpegDecode decodes any format ffmpeg reads to raw PCM (24kHz 16-bit
+// mono)
+func ffmpegDecode(data []byte) ([]byte, error) {
 	cmd := exec.Command("ffmpeg",
 		"-i", "pipe:0",
 		"-f", "s16le",
@@ -37,7 +49,7 @@ func DecodeOpusToPCM(opus []byte) ([]byte, error) {
 		"-ac", fmt.Sprintf("%d", ChannelCount),
 		"pipe:1",
 	)
-	cmd.Stdin = bytes.NewReader(opus)
+	cmd.Stdin = bytes.NewReader(data)
 	var out bytes.Buffer
 	cmd.Stdout = &out
 
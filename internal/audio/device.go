@@ -0,0 +1,98 @@
+package audio
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gen2brain/malgo"
+)
+
+// Device is an audio input or output as reported by the OS
+type Device struct {
+	Index   int // 1-based position in the list, as shown by vox devices
+	Name    string
+	ID      string // backend-specific identifier, stable for the session
+	Default bool   // the system default
+}
+
+// InputDevices lists the capture devices
+func InputDevices() ([]Device, error) {
+	return listDevices(malgo.Capture)
+}
+
+// OutputDevices lists the playback devices
+func OutputDevices() ([]Device, error) {
+	return listDevices(malgo.Playback)
+}
+
+func listDevices(kind malgo.DeviceType) ([]Device, error) {
+	ctx, err := malgo.InitContext(nil, malgo.ContextConfig{}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("init audio: %w", err)
+	}
+	defer ctx.Free()
+
+	infos, err := ctx.Devices(kind)
+	if err != nil {
+		return nil, fmt.Errorf("list devices: %w", err)
+	}
+	return toDevices(infos), nil
+}
+
+func toDevices(infos []malgo.DeviceInfo) []Device {
+	devices := make([]Device, len(infos))
+	for i, info := range infos {
+		devices[i] = Device{Index: i + 1, Name: info.Name(), ID: info.ID.String(), Default: info.IsDefault != 0}
+	}
+	return devices
+}
+
+// FindDevice picks the device a --input-device/--output-device value refers
+// to: its ID, its name, its number in the list, or a unique part of its name
+// (names are matched case-insensitively)
+func FindDevice(devices []Device, query string) (Device, error) {
+	for _, d := range devices {
+		if d.ID == query || strings.EqualFold(d.Name, query) {
+			return d, nil
+		}
+	}
+	if n, err := strconv.Atoi(query); err == nil && n >= 1 && n <= len(devices) {
+		return devices[n-1], nil
+	}
+
+	var matches []Device
+	for _, d := range devices {
+		if strings.Contains(strings.ToLower(d.Name), strings.ToLower(query)) {
+			matches = append(matches, d)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return Device{}, fmt.Errorf("no device matches %q (run: vox devices)", query)
+	case 1:
+		return matches[0], nil
+	}
+	names := make([]string, len(matches))
+	for i, d := range matches {
+		names[i] = d.Name
+	}
+	return Device{}, fmt.Errorf("%q matches several devices: %s", query, strings.Join(names, ", "))
+}
+
+// deviceID resolves a device query on ctx to the ID malgo opens. An empty
+// query means the system default, returned as nil.
+func deviceID(ctx malgo.Context, kind malgo.DeviceType, query string) (*malgo.DeviceID, error) {
+	if query == "" {
+		return nil, nil
+	}
+	infos, err := ctx.Devices(kind)
+	if err != nil {
+		return nil, fmt.Errorf("list devices: %w", err)
+	}
+	d, err := FindDevice(toDevices(infos), query)
+	if err != nil {
+		return nil, err
+	}
+	return &infos[d.Index-1].ID, nil
+}
@@ -0,0 +1,100 @@
+package flac
+
+import "errors"
+
+var errShortFrame = errors.New("flac: frame truncated")
+
+// bitWriter packs MSB-first bit fields
+type bitWriter struct {
+	buf []byte
+	acc uint64
+	n   uint // bits held in acc
+}
+
+func (w *bitWriter) bits(v uint64, n uint) {
+	for n > 0 {
+		take := min(n, 56-w.n)
+		n -= take
+		w.acc = w.acc<<take | (v>>n)&(1<<take-1)
+		w.n += take
+		for w.n >= 8 {
+			w.n -= 8
+			w.buf = append(w.buf, byte(w.acc>>w.n))
+		}
+	}
+}
+
+func (w *bitWriter) signed(v int32, n uint) {
+	w.bits(uint64(uint32(v)), n)
+}
+
+// unary writes q zero bits followed by a one
+func (w *bitWriter) unary(q uint32) {
+	for q >= 32 {
+		w.bits(0, 32)
+		q -= 32
+	}
+	w.bits(1, uint(q)+1)
+}
+
+// align pads with zero bits to a byte boundary
+func (w *bitWriter) align() {
+	if w.n > 0 {
+		w.bits(0, 8-w.n)
+	}
+}
+
+// bitReader unpacks MSB-first bit fields
+type bitReader struct {
+	data []byte
+	pos  int // in bits
+}
+
+func (r *bitReader) bits(n uint) (uint64, error) {
+	if r.pos+int(n) > len(r.data)*8 {
+		return 0, errShortFrame
+	}
+	var v uint64
+	for n > 0 {
+		byteBits := 8 - uint(r.pos%8)
+		take := min(n, byteBits)
+		b := uint64(r.data[r.pos/8]) >> (byteBits - take) & (1<<take - 1)
+		v = v<<take | b
+		r.pos += int(take)
+		n -= take
+	}
+	return v, nil
+}
+
+func (r *bitReader) signed(n uint) (int32, error) {
+	v, err := r.bits(n)
+	if err != nil || n == 0 {
+		return 0, err
+	}
+	return int32(int64(v<<(64-n)) >> (64 - n)), nil
+}
+
+// unary counts zero bits up to the next one
+func (r *bitReader) unary() (uint32, error) {
+	var q uint32
+	for {
+		if r.pos >= len(r.data)*8 {
+			return 0, errShortFrame
+		}
+		if r.pos%8 == 0 && r.data[r.pos/8] == 0 {
+			q += 8
+			r.pos += 8
+			continue
+		}
+		bit := r.data[r.pos/8] >> (7 - r.pos%8) & 1
+		r.pos++
+		if bit == 1 {
+			return q, nil
+		}
+		q++
+	}
+}
+
+func (r *bitReader) align() {
+	r.pos = (r.pos + 7) &^ 7
+}
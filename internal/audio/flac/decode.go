@@ -0,0 +1,274 @@
+package flac
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// decodeFrame decodes one mono frame, appending its samples to out
+func decodeFrame(frame []byte, info StreamInfo, out []int32) ([]int32, error) {
+	if len(frame) < 6 || frame[0] != 0xFF || frame[1]&0xFE != 0xF8 {
+		return nil, fmt.Errorf("flac: bad frame sync")
+	}
+	if crc16(frame[:len(frame)-2]) != binary.BigEndian.Uint16(frame[len(frame)-2:]) {
+		return nil, fmt.Errorf("flac: frame checksum mismatch")
+	}
+
+	r := bitReader{data: frame, pos: 16}
+	sizeCode, _ := r.bits(4)
+	rateCode, _ := r.bits(4)
+	channels, _ := r.bits(4)
+	depthCode, _ := r.bits(3)
+	r.bits(1)
+	if channels != 0 {
+		return nil, fmt.Errorf("flac: only mono streams are supported")
+	}
+	depth := info.BitsPerSample
+	switch depthCode {
+	case 0:
+	case 4:
+		depth = 16
+	default:
+		return nil, fmt.Errorf("flac: only 16-bit streams are supported")
+	}
+	if depth != bitsPerSample {
+		return nil, fmt.Errorf("flac: only 16-bit streams are supported")
+	}
+
+	// Frame (or sample) number, UTF-8 coded; only its length matters here
+	lead, err := r.bits(8)
+	if err != nil {
+		return nil, err
+	}
+	if lead&0x80 != 0 {
+		for b := lead << 1 & 0xFF; b&0x80 != 0; b = b << 1 & 0xFF {
+			r.bits(8)
+		}
+	}
+
+	var blockSize int
+	switch {
+	case sizeCode == 1:
+		blockSize = 192
+	case sizeCode >= 2 && sizeCode <= 5:
+		blockSize = 576 << (sizeCode - 2)
+	case sizeCode == 6:
+		v, _ := r.bits(8)
+		blockSize = int(v) + 1
+	case sizeCode == 7:
+		v, _ := r.bits(16)
+		blockSize = int(v) + 1
+	case sizeCode >= 8:
+		blockSize = 256 << (sizeCode - 8)
+	default:
+		return nil, fmt.Errorf("flac: reserved block size")
+	}
+	switch rateCode {
+	case 12:
+		r.bits(8)
+	case 13, 14:
+		r.bits(16)
+	case 15:
+		return nil, fmt.Errorf("flac: invalid sample rate code")
+	}
+	headerLen := r.pos / 8
+	want, err := r.bits(8)
+	if err != nil {
+		return nil, err
+	}
+	if crc8(frame[:headerLen]) != byte(want) {
+		return nil, fmt.Errorf("flac: frame header checksum mismatch")
+	}
+
+	start := len(out)
+	out, err = decodeSubframe(&r, depth, blockSize, out)
+	if err != nil {
+		return nil, err
+	}
+	if len(out)-start != blockSize {
+		return nil, errShortFrame
+	}
+	return out, nil
+}
+
+func decodeSubframe(r *bitReader, depth, blockSize int, out []int32) ([]int32, error) {
+	header, err := r.bits(8)
+	if err != nil {
+		return nil, err
+	}
+	if header&0x80 != 0 {
+		return nil, fmt.Errorf("flac: bad subframe header")
+	}
+	kind := int(header >> 1 & 0x3F)
+
+	wasted := 0
+	if header&1 != 0 {
+		q, err := r.unary()
+		if err != nil {
+			return nil, err
+		}
+		wasted = int(q) + 1
+		depth -= wasted
+	}
+
+	start := len(out)
+	switch {
+	case kind == subframeConstant:
+		v, err := r.signed(uint(depth))
+		if err != nil {
+			return nil, err
+		}
+		for range blockSize {
+			out = append(out, v)
+		}
+
+	case kind == subframeVerbatim:
+		for range blockSize {
+			v, err := r.signed(uint(depth))
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		}
+
+	case kind&0x38 == subframeFixed && kind&0x07 <= maxFixedOrder:
+		order := kind & 0x07
+		if out, err = readWarmup(r, depth, order, out); err != nil {
+			return nil, err
+		}
+		if out, err = readResidual(r, blockSize, order, out); err != nil {
+			return nil, err
+		}
+		x := out[start:]
+		for i := order; i < len(x); i++ {
+			switch order {
+			case 1:
+				x[i] += x[i-1]
+			case 2:
+				x[i] += 2*x[i-1] - x[i-2]
+			case 3:
+				x[i] += 3*x[i-1] - 3*x[i-2] + x[i-3]
+			case 4:
+				x[i] += 4*x[i-1] - 6*x[i-2] + 4*x[i-3] - x[i-4]
+			}
+		}
+
+	case kind&subframeLPC != 0:
+		order := kind&0x1F + 1
+		if out, err = readWarmup(r, depth, order, out); err != nil {
+			return nil, err
+		}
+		precision, _ := r.bits(4)
+		if precision == 15 {
+			return nil, fmt.Errorf("flac: invalid LPC precision")
+		}
+		shift, err := r.signed(5)
+		if err != nil {
+			return nil, err
+		}
+		if shift < 0 {
+			return nil, fmt.Errorf("flac: negative LPC shift")
+		}
+		coefs := make([]int64, order)
+		for i := range coefs {
+			c, err := r.signed(uint(precision) + 1)
+			if err != nil {
+				return nil, err
+			}
+			coefs[i] = int64(c)
+		}
+		if out, err = readResidual(r, blockSize, order, out); err != nil {
+			return nil, err
+		}
+		x := out[start:]
+		for i := order; i < len(x); i++ {
+			var pred int64
+			for j, c := range coefs {
+				pred += c * int64(x[i-j-1])
+			}
+			x[i] += int32(pred >> shift)
+		}
+
+	default:
+		return nil, fmt.Errorf("flac: reserved subframe type %d", kind)
+	}
+
+	if wasted > 0 {
+		for i := start; i < len(out); i++ {
+			out[i] <<= wasted
+		}
+	}
+	return out, nil
+}
+
+func readWarmup(r *bitReader, depth, order int, out []int32) ([]int32, error) {
+	for range order {
+		v, err := r.signed(uint(depth))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// readResidual appends the Rice-coded residual for a block
+func readResidual(r *bitReader, blockSize, predOrder int, out []int32) ([]int32, error) {
+	method, err := r.bits(2)
+	if err != nil {
+		return nil, err
+	}
+	paramBits, escape := uint(4), uint64(15)
+	switch method {
+	case 0:
+	case 1:
+		paramBits, escape = 5, 31
+	default:
+		return nil, fmt.Errorf("flac: reserved residual coding method")
+	}
+
+	partOrder, err := r.bits(4)
+	if err != nil {
+		return nil, err
+	}
+	if blockSize>>partOrder < predOrder {
+		return nil, fmt.Errorf("flac: bad partition order")
+	}
+	for part := range 1 << partOrder {
+		n := blockSize >> partOrder
+		if part == 0 {
+			n -= predOrder
+		}
+		k, err := r.bits(paramBits)
+		if err != nil {
+			return nil, err
+		}
+		if k == escape {
+			raw, err := r.bits(5)
+			if err != nil {
+				return nil, err
+			}
+			for range n {
+				v, err := r.signed(uint(raw))
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, v)
+			}
+			continue
+		}
+		for range n {
+			q, err := r.unary()
+			if err != nil {
+				return nil, err
+			}
+			low, err := r.bits(uint(k))
+			if err != nil {
+				return nil, err
+			}
+			u := q<<k | uint32(low)
+			out = append(out, int32(u>>1)^-int32(u&1))
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,210 @@
+package flac
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+const (
+	bitsPerSample = 16
+	maxFixedOrder = 4
+	// maxPartitionOrder bounds the residual partition search
+	maxPartitionOrder = 6
+	// maxRiceParam is the largest parameter the 4-bit field can hold
+	// (15 is the escape code)
+	maxRiceParam = 14
+)
+
+// Subframe types
+const (
+	subframeConstant = 0x00
+	subframeVerbatim = 0x01
+	subframeFixed    = 0x08 // | order
+	subframeLPC      = 0x20 // | order-1
+)
+
+// encodeFrame encodes one block of mono 16-bit samples as a FLAC frame
+func encodeFrame(samples []int32, frameNum uint64) []byte {
+	var w bitWriter
+
+	// Header: sync + fixed blocking, block size and rate taken from the
+	// 16-bit field and STREAMINFO, mono, 16-bit
+	w.bits(0xFFF8, 16)
+	w.bits(0x7, 4) // block size: 16-bit (n-1) follows the frame number
+	w.bits(0x0, 4) // sample rate: from STREAMINFO
+	w.bits(0x0, 4) // channels: mono
+	w.bits(0x4, 3) // sample size: 16 bits
+	w.bits(0, 1)   // reserved
+	w.buf = append(w.buf, utf8Number(frameNum)...)
+	w.bits(uint64(len(samples)-1), 16)
+	w.buf = append(w.buf, crc8(w.buf))
+
+	encodeSubframe(&w, samples)
+
+	w.align()
+	return binary.BigEndian.AppendUint16(w.buf, crc16(w.buf))
+}
+
+// encodeSubframe picks the cheapest of constant, verbatim and fixed-order
+// prediction for the block
+func encodeSubframe(w *bitWriter, x []int32) {
+	constant := true
+	for _, v := range x[1:] {
+		if v != x[0] {
+			constant = false
+			break
+		}
+	}
+	if constant {
+		w.bits(subframeConstant<<1, 8)
+		w.signed(x[0], bitsPerSample)
+		return
+	}
+
+	bestCost := 8 + bitsPerSample*len(x) // verbatim
+	bestOrder := -1
+	var best residualPlan
+	residual := make([]int32, len(x))
+	for order := 0; order <= maxFixedOrder && order < len(x); order++ {
+		fixedResidual(x, order, residual)
+		plan := planResidual(residual[order:], len(x), order)
+		if cost := 8 + bitsPerSample*order + plan.cost; cost < bestCost {
+			bestCost, bestOrder, best = cost, order, plan
+		}
+	}
+
+	if bestOrder < 0 {
+		w.bits(subframeVerbatim<<1, 8)
+		for _, v := range x {
+			w.signed(v, bitsPerSample)
+		}
+		return
+	}
+
+	w.bits(uint64(subframeFixed|bestOrder)<<1, 8)
+	for _, v := range x[:bestOrder] {
+		w.signed(v, bitsPerSample)
+	}
+	fixedResidual(x, bestOrder, residual)
+	writeResidual(w, residual[bestOrder:], len(x), bestOrder, best)
+}
+
+// fixedResidual computes the error of FLAC's fixed polynomial predictor
+func fixedResidual(x []int32, order int, out []int32) {
+	for i := order; i < len(x); i++ {
+		var pred int32
+		switch order {
+		case 1:
+			pred = x[i-1]
+		case 2:
+			pred = 2*x[i-1] - x[i-2]
+		case 3:
+			pred = 3*x[i-1] - 3*x[i-2] + x[i-3]
+		case 4:
+			pred = 4*x[i-1] - 6*x[i-2] + 4*x[i-3] - x[i-4]
+		}
+		out[i] = x[i] - pred
+	}
+}
+
+// residualPlan is a partitioning of the residual with a Rice parameter
+// per partition
+type residualPlan struct {
+	order  int
+	params []uint
+	cost   int // in bits
+}
+
+// planResidual finds the partition order and Rice parameters that code the
+// residual in the fewest bits
+func planResidual(res []int32, blockSize, predOrder int) residualPlan {
+	best := residualPlan{cost: -1}
+	for p := 0; p <= maxPartitionOrder; p++ {
+		if blockSize%(1<<p) != 0 || blockSize>>p <= predOrder {
+			break
+		}
+		plan := residualPlan{order: p, cost: 6}
+		start := 0
+		for part := range 1 << p {
+			n := blockSize >> p
+			if part == 0 {
+				n -= predOrder
+			}
+			k, bits := riceParam(res[start : start+n])
+			plan.params = append(plan.params, k)
+			plan.cost += 4 + bits
+			start += n
+		}
+		if best.cost < 0 || plan.cost < best.cost {
+			best = plan
+		}
+	}
+	return best
+}
+
+// riceParam picks the Rice parameter for a partition and returns its cost
+func riceParam(res []int32) (uint, int) {
+	var sum uint64
+	for _, r := range res {
+		sum += uint64(zigzag(r))
+	}
+	// The optimum is near log2 of the mean; check its neighbours exactly
+	guess := 0
+	if mean := sum / uint64(max(len(res), 1)); mean > 0 {
+		guess = bits.Len64(mean) - 1
+	}
+	bestK, bestCost := uint(0), -1
+	for k := max(guess-1, 0); k <= min(guess+1, maxRiceParam); k++ {
+		cost := len(res) * (k + 1)
+		for _, r := range res {
+			cost += int(zigzag(r) >> k)
+		}
+		if bestCost < 0 || cost < bestCost {
+			bestK, bestCost = uint(k), cost
+		}
+	}
+	return bestK, bestCost
+}
+
+func writeResidual(w *bitWriter, res []int32, blockSize, predOrder int, plan residualPlan) {
+	w.bits(0, 2) // Rice coding with 4-bit parameters
+	w.bits(uint64(plan.order), 4)
+	start := 0
+	for part, k := range plan.params {
+		n := blockSize >> plan.order
+		if part == 0 {
+			n -= predOrder
+		}
+		w.bits(uint64(k), 4)
+		for _, r := range res[start : start+n] {
+			u := zigzag(r)
+			w.unary(u >> k)
+			w.bits(uint64(u), k)
+		}
+		start += n
+	}
+}
+
+// zigzag folds signed residuals onto unsigned: 0, -1, 1, -2, ...
+func zigzag(v int32) uint32 {
+	return uint32(v<<1) ^ uint32(v>>31)
+}
+
+// utf8Number encodes a frame number the way FLAC does: UTF-8 extended to 36
+// bits
+func utf8Number(v uint64) []byte {
+	if v < 0x80 {
+		return []byte{byte(v)}
+	}
+	n := 2
+	for v >= 1<<(5*n+1) && n < 7 {
+		n++
+	}
+	out := make([]byte, n)
+	for i := n - 1; i > 0; i-- {
+		out[i] = 0x80 | byte(v&0x3F)
+		v >>= 6
+	}
+	out[0] = byte(0xFF<<(8-n)) | byte(v)
+	return out
+}
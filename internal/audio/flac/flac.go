@@ -0,0 +1,188 @@
+// Package flac encodes and decodes 16-bit mono FLAC, the lossless codec used
+// for the TTS cache. The cache carries streams in Ogg (the Ogg FLAC
+// mapping); native .flac files can be written for export.
+package flac
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// BlockSize is the number of samples per frame. 4096 is the reference
+// encoder's default for rates up to 48kHz.
+const BlockSize = 4096
+
+// ErrNotFLAC is returned when a stream isn't FLAC
+var ErrNotFLAC = errors.New("not a FLAC stream")
+
+// StreamInfo is the STREAMINFO metadata block
+type StreamInfo struct {
+	MinBlockSize  int
+	MaxBlockSize  int
+	SampleRate    int
+	Channels      int
+	BitsPerSample int
+	TotalSamples  int64
+	MD5           [16]byte // of the decoded samples, zero if unknown
+}
+
+const streamInfoLen = 34
+
+func (s StreamInfo) marshal() []byte {
+	var w bitWriter
+	w.bits(uint64(s.MinBlockSize), 16)
+	w.bits(uint64(s.MaxBlockSize), 16)
+	w.bits(0, 24) // min frame size: unknown
+	w.bits(0, 24) // max frame size: unknown
+	w.bits(uint64(s.SampleRate), 20)
+	w.bits(uint64(s.Channels-1), 3)
+	w.bits(uint64(s.BitsPerSample-1), 5)
+	w.bits(uint64(s.TotalSamples), 36)
+	return append(w.buf, s.MD5[:]...)
+}
+
+func parseStreamInfo(b []byte) (StreamInfo, error) {
+	if len(b) < streamInfoLen {
+		return StreamInfo{}, fmt.Errorf("flac: STREAMINFO too short")
+	}
+	r := bitReader{data: b}
+	minBlock, _ := r.bits(16)
+	maxBlock, _ := r.bits(16)
+	r.bits(48) // frame sizes
+	rate, _ := r.bits(20)
+	ch, _ := r.bits(3)
+	bps, _ := r.bits(5)
+	total, _ := r.bits(36)
+	s := StreamInfo{
+		MinBlockSize:  int(minBlock),
+		MaxBlockSize:  int(maxBlock),
+		SampleRate:    int(rate),
+		Channels:      int(ch) + 1,
+		BitsPerSample: int(bps) + 1,
+		TotalSamples:  int64(total),
+	}
+	copy(s.MD5[:], b[18:34])
+	return s, nil
+}
+
+// Encode compresses 16-bit little-endian mono PCM into a native FLAC file
+func Encode(pcm []byte, sampleRate int) []byte {
+	info, samples := prepare(pcm, sampleRate)
+	comment := vorbisComment()
+
+	out := []byte("fLaC")
+	out = append(out, metadataHeader(blockStreamInfo, streamInfoLen, false)...)
+	out = append(out, info.marshal()...)
+	out = append(out, metadataHeader(blockVorbisComment, len(comment), true)...)
+	out = append(out, comment...)
+
+	var frameNum uint64
+	for start := 0; start < len(samples); start += BlockSize {
+		out = append(out, encodeFrame(samples[start:min(start+BlockSize, len(samples))], frameNum)...)
+		frameNum++
+	}
+	return out
+}
+
+// prepare unpacks PCM into samples and describes the stream
+func prepare(pcm []byte, sampleRate int) (StreamInfo, []int32) {
+	samples := make([]int32, len(pcm)/2)
+	for i := range samples {
+		samples[i] = int32(int16(binary.LittleEndian.Uint16(pcm[i*2:])))
+	}
+
+	info := StreamInfo{
+		MinBlockSize:  BlockSize,
+		MaxBlockSize:  BlockSize,
+		SampleRate:    sampleRate,
+		Channels:      1,
+		BitsPerSample: bitsPerSample,
+		TotalSamples:  int64(len(samples)),
+		MD5:           md5.Sum(pcm[:len(samples)*2]),
+	}
+	if len(samples) < BlockSize {
+		info.MinBlockSize = max(len(samples), 16)
+		info.MaxBlockSize = info.MinBlockSize
+	}
+	return info, samples
+}
+
+// vendor is recorded in the VORBIS_COMMENT block
+const vendor = "vox"
+
+// vorbisComment builds a VORBIS_COMMENT block with no tags, which the Ogg
+// mapping requires and players expect
+func vorbisComment() []byte {
+	b := binary.LittleEndian.AppendUint32(nil, uint32(len(vendor)))
+	b = append(b, vendor...)
+	return binary.LittleEndian.AppendUint32(b, 0)
+}
+
+// metadataHeader builds the 4-byte header in front of a metadata block
+func metadataHeader(blockType byte, length int, last bool) []byte {
+	h := make([]byte, 4)
+	binary.BigEndian.PutUint32(h, uint32(length))
+	h[0] = blockType
+	if last {
+		h[0] |= 0x80
+	}
+	return h
+}
+
+// Metadata block types
+const (
+	blockStreamInfo    = 0
+	blockVorbisComment = 4
+)
+
+var crc8Table = func() [256]byte {
+	var t [256]byte
+	for i := range t {
+		c := byte(i)
+		for range 8 {
+			if c&0x80 != 0 {
+				c = c<<1 ^ 0x07
+			} else {
+				c <<= 1
+			}
+		}
+		t[i] = c
+	}
+	return t
+}()
+
+var crc16Table = func() [256]uint16 {
+	var t [256]uint16
+	for i := range t {
+		c := uint16(i) << 8
+		for range 8 {
+			if c&0x8000 != 0 {
+				c = c<<1 ^ 0x8005
+			} else {
+				c <<= 1
+			}
+		}
+		t[i] = c
+	}
+	return t
+}()
+
+// crc8 protects frame headers
+func crc8(b []byte) byte {
+	var c byte
+	for _, v := range b {
+		c = crc8Table[c^v]
+	}
+	return c
+}
+
+// crc16 protects whole frames
+func crc16(b []byte) uint16 {
+	var c uint16
+	for _, v := range b {
+		c = c<<8 ^ crc16Table[byte(c>>8)^v]
+	}
+	return c
+}
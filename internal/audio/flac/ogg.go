@@ -0,0 +1,92 @@
+package flac
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ontypehq/vox/internal/audio/ogg"
+)
+
+// EncodeOgg compresses 16-bit little-endian mono PCM into an Ogg FLAC stream
+func EncodeOgg(pcm []byte, sampleRate int) []byte {
+	info, samples := prepare(pcm, sampleRate)
+
+	var buf bytes.Buffer
+	w := ogg.NewWriter(&buf, 0x766f78) // "vox"
+
+	// First packet: mapping header, "fLaC" and STREAMINFO, alone on the
+	// first page. One more header packet (the comment block) follows.
+	head := []byte{0x7F, 'F', 'L', 'A', 'C', 1, 0, 0, 1}
+	head = append(head, "fLaC"...)
+	head = append(head, metadataHeader(blockStreamInfo, streamInfoLen, false)...)
+	head = append(head, info.marshal()...)
+	w.WritePacket(head, 0)
+	w.Flush()
+
+	comment := vorbisComment()
+	w.WritePacket(append(metadataHeader(blockVorbisComment, len(comment), true), comment...), 0)
+	w.Flush()
+
+	var frameNum uint64
+	for start := 0; start < len(samples); start += BlockSize {
+		block := samples[start:min(start+BlockSize, len(samples))]
+		w.WritePacket(encodeFrame(block, frameNum), int64(start+len(block)))
+		frameNum++
+	}
+	w.Close()
+	return buf.Bytes()
+}
+
+// DecodeOgg decompresses an Ogg FLAC stream to 16-bit little-endian mono PCM.
+// It returns ErrNotFLAC for Ogg streams of another codec (e.g. Opus) and
+// ogg.ErrNotOgg for data that isn't Ogg at all.
+func DecodeOgg(data []byte) (pcm []byte, sampleRate int, err error) {
+	r := ogg.NewReader(bytes.NewReader(data))
+	head, err := r.ReadPacket()
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(head) < 13+4+streamInfoLen || !bytes.HasPrefix(head, []byte("\x7FFLAC")) || string(head[9:13]) != "fLaC" {
+		return nil, 0, ErrNotFLAC
+	}
+	info, err := parseStreamInfo(head[17:])
+	if err != nil {
+		return nil, 0, err
+	}
+	if info.Channels != 1 || info.BitsPerSample != bitsPerSample {
+		return nil, 0, fmt.Errorf("flac: only 16-bit mono streams are supported, got %d-bit %d-channel", info.BitsPerSample, info.Channels)
+	}
+
+	// TotalSamples comes from the file, so a corrupt header could ask for
+	// any amount; reserve no more samples than the file has bytes and let
+	// append grow past that for well-compressed audio
+	samples := make([]int32, 0, min(info.TotalSamples, int64(len(data))))
+	for {
+		packet, err := r.ReadPacket()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(packet) == 0 || packet[0] != 0xFF {
+			continue // header packet (metadata block)
+		}
+		if samples, err = decodeFrame(packet, info, samples); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	pcm = make([]byte, len(samples)*2)
+	for i, v := range samples {
+		binary.LittleEndian.PutUint16(pcm[i*2:], uint16(int16(v)))
+	}
+	if info.MD5 != [16]byte{} && md5.Sum(pcm) != info.MD5 {
+		return nil, 0, fmt.Errorf("flac: decoded audio doesn't match its checksum")
+	}
+	return pcm, info.SampleRate, nil
+}
@@ -0,0 +1,115 @@
+package flac
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/ontypehq/vox/internal/audio/ogg"
+)
+
+// testPCM returns n samples of a tone with some noise, and a stretch of
+// silence, as 16-bit little-endian PCM
+func testPCM(n int) []byte {
+	rng := rand.New(rand.NewSource(1))
+	pcm := make([]byte, n*2)
+	for i := range n {
+		var v float64
+		if i%20000 < 15000 {
+			v = 12000*math.Sin(float64(i)*0.05) + rng.NormFloat64()*300
+		}
+		binary.LittleEndian.PutUint16(pcm[i*2:], uint16(int16(v)))
+	}
+	return pcm
+}
+
+func TestOggRoundTrip(t *testing.T) {
+	for _, n := range []int{0, 1, BlockSize - 1, BlockSize, BlockSize + 1, 3 * 24000} {
+		pcm := testPCM(n)
+		got, rate, err := DecodeOgg(EncodeOgg(pcm, 24000))
+		if err != nil {
+			t.Fatalf("%d samples: %v", n, err)
+		}
+		if rate != 24000 {
+			t.Errorf("%d samples: rate %d, want 24000", n, rate)
+		}
+		if !bytes.Equal(got, pcm) {
+			t.Errorf("%d samples: decoded audio differs (%d bytes, want %d)", n, len(got), len(pcm))
+		}
+	}
+}
+
+func TestOggCompresses(t *testing.T) {
+	pcm := testPCM(3 * 24000)
+	if enc := EncodeOgg(pcm, 24000); len(enc) >= len(pcm) {
+		t.Errorf("encoded %d bytes of PCM to %d", len(pcm), len(enc))
+	}
+}
+
+func TestEncodeNative(t *testing.T) {
+	data := Encode(testPCM(BlockSize+10), 16000)
+	if !bytes.HasPrefix(data, []byte("fLaC")) {
+		t.Fatalf("native FLAC starts with %q", data[:4])
+	}
+	info, err := parseStreamInfo(data[8:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.SampleRate != 16000 || info.Channels != 1 || info.BitsPerSample != 16 || info.TotalSamples != BlockSize+10 {
+		t.Errorf("STREAMINFO %+v", info)
+	}
+}
+
+func TestDecodeOggRejects(t *testing.T) {
+	valid := EncodeOgg(testPCM(2*BlockSize), 24000)
+
+	var opus bytes.Buffer
+	w := ogg.NewWriter(&opus, 1)
+	w.WritePacket([]byte("OpusHead\x01\x01\x38\x01\x80\xbb\x00\x00\x00\x00\x00"), 0)
+	w.Close()
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"empty", nil, nil},
+		{"not ogg", []byte("RIFF....WAVEfmt "), ogg.ErrNotOgg},
+		{"opus", opus.Bytes(), ErrNotFLAC},
+		{"truncated", valid[:len(valid)/2], nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := DecodeOgg(tt.data)
+			if err == nil {
+				t.Fatal("decoded without error")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("error %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// A corrupt header must not make the decoder reserve memory for the sample
+// count it claims
+func TestDecodeOggHugeSampleCount(t *testing.T) {
+	info := StreamInfo{MinBlockSize: BlockSize, MaxBlockSize: BlockSize, SampleRate: 24000, Channels: 1, BitsPerSample: 16, TotalSamples: 1<<36 - 1}
+	head := []byte{0x7F, 'F', 'L', 'A', 'C', 1, 0, 0, 1}
+	head = append(head, "fLaC"...)
+	head = append(head, metadataHeader(blockStreamInfo, streamInfoLen, true)...)
+	head = append(head, info.marshal()...)
+
+	var buf bytes.Buffer
+	w := ogg.NewWriter(&buf, 1)
+	w.WritePacket(head, 0)
+	w.Close()
+
+	pcm, _, err := DecodeOgg(buf.Bytes())
+	if err == nil && len(pcm) != 0 {
+		t.Errorf("decoded %d bytes from a stream without frames", len(pcm))
+	}
+}
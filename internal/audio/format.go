@@ -0,0 +1,207 @@
+package audio
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ontypehq/vox/internal/audio/flac"
+	"github.com/ontypehq/vox/internal/audio/g711"
+	"github.com/ontypehq/vox/internal/audio/wav"
+)
+
+// OutputFormat encodes 16-bit mono PCM into a file format
+type OutputFormat struct {
+	Name       string
+	Extensions []string // with dot, first is the preferred one
+	Help       string
+	// FixedRate is the only sample rate the format supports, 0 if any
+	FixedRate int
+	// Tool is an external program the encoder runs, checked by Available
+	Tool string
+	// Encode receives PCM already resampled to the output rate
+	Encode func(pcm []byte, sampleRate int) ([]byte, error)
+	// StreamHeader is set for formats whose body is the PCM itself, so
+	// audio can be written as it arrives: the header, then raw chunks
+	StreamHeader func(sampleRate int) []byte
+}
+
+// Available reports why the format can't be written on this machine
+func (f OutputFormat) Available() error {
+	if f.Tool == "" {
+		return nil
+	}
+	if _, err := exec.LookPath(f.Tool); err != nil {
+		return fmt.Errorf("%s output needs %s, which is not installed", f.Name, f.Tool)
+	}
+	return nil
+}
+
+var outputFormats = map[string]OutputFormat{}
+
+// RegisterFormat makes an output format available by name and extension
+func RegisterFormat(f OutputFormat) {
+	outputFormats[f.Name] = f
+}
+
+// FormatNames returns the registered output format names, sorted
+func FormatNames() []string {
+	names := make([]string, 0, len(outputFormats))
+	for name := range outputFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupFormat returns the output format registered under name
+func LookupFormat(name string) (OutputFormat, error) {
+	f, ok := outputFormats[strings.ToLower(name)]
+	if !ok {
+		return OutputFormat{}, fmt.Errorf("unknown output format %q (available: %s)", name, strings.Join(FormatNames(), ", "))
+	}
+	return f, nil
+}
+
+// FormatForPath picks the output format from a file extension. Unknown or
+// missing extensions get WAV.
+func FormatForPath(path string) OutputFormat {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, name := range FormatNames() {
+		f := outputFormats[name]
+		for _, e := range f.Extensions {
+			if e == ext {
+				return f
+			}
+		}
+	}
+	return outputFormats["wav"]
+}
+
+// EncodeAs converts PCM at sampleRate into format f at outRate (0 keeps the
+// input rate, or uses the format's fixed rate)
+func EncodeAs(f OutputFormat, pcm []byte, sampleRate, outRate int) ([]byte, error) {
+	if f.FixedRate != 0 {
+		if outRate != 0 && outRate != f.FixedRate {
+			return nil, fmt.Errorf("%s is only defined at %d Hz", f.Name, f.FixedRate)
+		}
+		outRate = f.FixedRate
+	}
+	if outRate == 0 {
+		outRate = sampleRate
+	}
+	if outRate != sampleRate {
+		a := wav.Audio{Format: wav.Format{SampleRate: sampleRate, Channels: 1, BitsPerSample: 16}, Data: pcm}
+		pcm = a.Convert(outRate)
+	}
+	return f.Encode(pcm, outRate)
+}
+
+func init() {
+	RegisterFormat(OutputFormat{
+		Name:       "wav",
+		Extensions: []string{".wav"},
+		Help:       "16-bit PCM WAV",
+		Encode: func(pcm []byte, rate int) ([]byte, error) {
+			return wav.Encode(pcm, rate), nil
+		},
+		StreamHeader: wav.StreamHeader,
+	})
+	RegisterFormat(OutputFormat{
+		Name:       "pcm",
+		Extensions: []string{".pcm", ".raw", ".s16le"},
+		Help:       "headerless signed 16-bit little-endian PCM",
+		Encode: func(pcm []byte, rate int) ([]byte, error) {
+			return pcm, nil
+		},
+		StreamHeader: func(int) []byte { return nil },
+	})
+	RegisterFormat(OutputFormat{
+		Name:       "flac",
+		Extensions: []string{".flac"},
+		Help:       "lossless FLAC",
+		Encode: func(pcm []byte, rate int) ([]byte, error) {
+			return flac.Encode(pcm, rate), nil
+		},
+	})
+	RegisterFormat(OutputFormat{
+		Name:       "opus",
+		Extensions: []string{".opus", ".ogg", ".oga"},
+		Help:       "Ogg/Opus at 24 kbps (needs ffmpeg)",
+		Tool:       "ffmpeg",
+		Encode: func(pcm []byte, rate int) ([]byte, error) {
+			return ffmpegEncode(pcm, rate, "-c:a", "libopus", "-b:a", "24k", "-f", "opus")
+		},
+	})
+	RegisterFormat(OutputFormat{
+		Name:       "mp3",
+		Extensions: []string{".mp3"},
+		Help:       "MP3 at 64 kbps (needs ffmpeg)",
+		Tool:       "ffmpeg",
+		Encode: func(pcm []byte, rate int) ([]byte, error) {
+			return ffmpegEncode(pcm, rate, "-c:a", "libmp3lame", "-b:a", "64k", "-f", "mp3")
+		},
+	})
+	RegisterFormat(OutputFormat{
+		Name:       "ulaw",
+		Extensions: []string{".ulaw", ".ul", ".mulaw"},
+		Help:       "raw 8 kHz G.711 μ-law",
+		FixedRate:  g711.SampleRate,
+		Encode: func(pcm []byte, rate int) ([]byte, error) {
+			return g711.EncodeULaw(pcm), nil
+		},
+	})
+	RegisterFormat(OutputFormat{
+		Name:       "alaw",
+		Extensions: []string{".alaw", ".al"},
+		Help:       "raw 8 kHz G.711 A-law",
+		FixedRate:  g711.SampleRate,
+		Encode: func(pcm []byte, rate int) ([]byte, error) {
+			return g711.EncodeALaw(pcm), nil
+		},
+	})
+	RegisterFormat(OutputFormat{
+		Name:      "wav-ulaw",
+		Help:      "8 kHz G.711 μ-law in a WAV container",
+		FixedRate: g711.SampleRate,
+		Encode: func(pcm []byte, rate int) ([]byte, error) {
+			return wav.EncodeG711(g711.EncodeULaw(pcm), rate, wav.TagULaw), nil
+		},
+	})
+	RegisterFormat(OutputFormat{
+		Name:      "wav-alaw",
+		Help:      "8 kHz G.711 A-law in a WAV container",
+		FixedRate: g711.SampleRate,
+		Encode: func(pcm []byte, rate int) ([]byte, error) {
+			return wav.EncodeG711(g711.EncodeALaw(pcm), rate, wav.TagALaw), nil
+		},
+	})
+}
+
+// ffmpegEncode pipes 16-bit mono PCM through ffmpeg with the given output
+// arguments
+func ffmpegEncode(pcm []byte, rate int, outArgs ...string) ([]byte, error) {
+	args := []string{
+		"-loglevel", "error",
+		"-f", "s16le",
+		"-ar", fmt.Sprintf("%d", rate),
+		"-ac", "1",
+		"-i", "pipe:0",
+	}
+	cmd := exec.Command("ffmpeg", append(append(args, outArgs...), "pipe:1")...)
+	cmd.Stdin = bytes.NewReader(pcm)
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("ffmpeg encode: %w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("ffmpeg encode: %w", err)
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,70 @@
+// Package g711 encodes 16-bit PCM as G.711 μ-law or A-law, the 8-bit
+// companded formats used by telephone networks and IVR systems
+package g711
+
+import "encoding/binary"
+
+// SampleRate is the only rate G.711 is defined for
+const SampleRate = 8000
+
+// EncodeULaw converts 16-bit little-endian PCM to μ-law bytes
+func EncodeULaw(pcm []byte) []byte {
+	out := make([]byte, len(pcm)/2)
+	for i := range out {
+		out[i] = ulaw(int16(binary.LittleEndian.Uint16(pcm[i*2:])))
+	}
+	return out
+}
+
+// EncodeALaw converts 16-bit little-endian PCM to A-law bytes
+func EncodeALaw(pcm []byte) []byte {
+	out := make([]byte, len(pcm)/2)
+	for i := range out {
+		out[i] = alaw(int16(binary.LittleEndian.Uint16(pcm[i*2:])))
+	}
+	return out
+}
+
+const (
+	ulawBias = 0x84
+	ulawClip = 32635
+)
+
+func ulaw(s int16) byte {
+	v := int(s)
+	sign := 0
+	if v < 0 {
+		v = -v
+		sign = 0x80
+	}
+	v = min(v, ulawClip) + ulawBias
+
+	exp := 7
+	for mask := 0x4000; v&mask == 0 && exp > 0; mask >>= 1 {
+		exp--
+	}
+	mantissa := v >> (exp + 3) & 0x0F
+	return ^byte(sign | exp<<4 | mantissa)
+}
+
+func alaw(s int16) byte {
+	v := int(s) >> 3 // A-law works on 13-bit samples
+	sign := 0x80
+	if v < 0 {
+		v = -v - 1
+		sign = 0
+	}
+	v = min(v, 0xFFF)
+
+	var b int
+	if v < 32 {
+		b = v >> 1
+	} else {
+		exp := 1
+		for v >= 64<<(exp-1) && exp < 7 {
+			exp++
+		}
+		b = exp<<4 | v>>exp&0x0F
+	}
+	return byte(sign|b) ^ 0x55
+}
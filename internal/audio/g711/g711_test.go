@@ -0,0 +1,91 @@
+package g711
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+// Reference decoders (ITU-T G.711), independent of the encoder
+func decodeULaw(b byte) int {
+	b = ^b
+	exp := int(b>>4) & 7
+	v := (int(b&0x0F)<<3+ulawBias)<<exp - ulawBias
+	if b&0x80 != 0 {
+		return -v
+	}
+	return v
+}
+
+func decodeALaw(b byte) int {
+	b ^= 0x55
+	exp := int(b>>4) & 7
+	v := int(b&0x0F)<<4 + 8
+	if exp > 0 {
+		v = (int(b&0x0F)<<4 + 0x108) << (exp - 1)
+	}
+	if b&0x80 == 0 {
+		return -v
+	}
+	return v
+}
+
+// everySample is all 65536 16-bit values in order, as PCM
+func everySample() []byte {
+	pcm := make([]byte, 65536*2)
+	for i := range 65536 {
+		binary.LittleEndian.PutUint16(pcm[i*2:], uint16(int16(i-32768)))
+	}
+	return pcm
+}
+
+func TestRoundTrip(t *testing.T) {
+	codecs := []struct {
+		name   string
+		encode func([]byte) []byte
+		decode func(byte) int
+	}{
+		{"ulaw", EncodeULaw, decodeULaw},
+		{"alaw", EncodeALaw, decodeALaw},
+	}
+	pcm := everySample()
+	for _, c := range codecs {
+		t.Run(c.name, func(t *testing.T) {
+			enc := c.encode(pcm)
+			if len(enc) != 65536 {
+				t.Fatalf("encoded %d samples to %d bytes", 65536, len(enc))
+			}
+			prev := math.MinInt
+			for i, b := range enc {
+				in := i - 32768
+				out := c.decode(b)
+				// Companding keeps about 4 bits of mantissa: the error grows
+				// with the level but stays within 1/16 of it
+				if d := abs(out - in); d > abs(in)/16+16 {
+					t.Fatalf("%d decodes as %d", in, out)
+				}
+				if out < prev {
+					t.Fatalf("not monotonic: %d decodes as %d, below %d", in, out, prev)
+				}
+				prev = out
+			}
+		})
+	}
+}
+
+func TestSilence(t *testing.T) {
+	zero := make([]byte, 2)
+	if b := EncodeULaw(zero)[0]; b != 0xFF {
+		t.Errorf("μ-law silence is 0x%02X, want 0xFF", b)
+	}
+	if b := EncodeALaw(zero)[0]; b != 0xD5 {
+		t.Errorf("A-law silence is 0x%02X, want 0xD5", b)
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
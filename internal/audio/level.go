@@ -0,0 +1,103 @@
+package audio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sync"
+)
+
+// Level is the loudness of one captured block, as linear amplitude (0-1)
+type Level struct {
+	RMS  float64
+	Peak float64
+}
+
+const (
+	// clipLevel is the peak treated as clipping (-0.1 dBFS)
+	clipLevel = 0.989
+	// silentLevel is the peak below which the input is taken to be dead,
+	// e.g. a muted microphone (-60 dBFS)
+	silentLevel = 0.001
+	// quietLevel is the loudest RMS a recording should at least reach
+	// while someone speaks (-40 dBFS)
+	quietLevel = 0.01
+	// maxClippedBlocks is how many clipped blocks are tolerated before
+	// clipping is reported
+	maxClippedBlocks = 3
+)
+
+// DB converts a linear amplitude (0-1) to dBFS
+func DB(level float64) float64 {
+	if level <= 0 {
+		return math.Inf(-1)
+	}
+	return 20 * math.Log10(level)
+}
+
+// blockLevel measures a block of 16-bit PCM (any channel count)
+func blockLevel(pcm []byte) Level {
+	n := len(pcm) / 2
+	if n == 0 {
+		return Level{}
+	}
+	var sum, peak float64
+	for i := range n {
+		s := float64(int16(binary.LittleEndian.Uint16(pcm[i*2:]))) / 32768
+		sum += s * s
+		peak = max(peak, math.Abs(s))
+	}
+	return Level{RMS: math.Sqrt(sum / float64(n)), Peak: peak}
+}
+
+// Clipping reports whether the block hit full scale
+func (l Level) Clipping() bool {
+	return l.Peak >= clipLevel
+}
+
+// LevelStats sums up the levels of a recording to catch a muted, badly
+// placed or overdriven microphone. Safe for concurrent use.
+type LevelStats struct {
+	mu      sync.Mutex
+	blocks  int
+	peak    float64
+	loudest float64 // highest block RMS
+	clipped int
+}
+
+func (s *LevelStats) Add(l Level) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blocks++
+	s.peak = max(s.peak, l.Peak)
+	s.loudest = max(s.loudest, l.RMS)
+	if l.Clipping() {
+		s.clipped++
+	}
+}
+
+// Silent reports whether nothing above the noise of a dead input has been
+// captured so far
+func (s *LevelStats) Silent() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.peak < silentLevel
+}
+
+// Problem describes what is wrong with the input level, or returns "" if
+// it looks usable
+func (s *LevelStats) Problem() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case s.blocks == 0:
+		return ""
+	case s.peak < silentLevel:
+		return "no input signal — is the microphone muted or the wrong device selected? (see vox devices)"
+	case s.loudest < quietLevel:
+		return fmt.Sprintf("input is very quiet (loudest %.0f dBFS) — move closer or raise the input gain", DB(s.loudest))
+	case s.clipped > maxClippedBlocks:
+		return fmt.Sprintf("input clipped %d times — lower the input gain or move back from the microphone", s.clipped)
+	}
+	return ""
+}
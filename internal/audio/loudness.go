@@ -0,0 +1,249 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Loudness measurement follows ITU-R BS.1770 / EBU R128: K-weighted mean
+// square over 400ms blocks overlapping by 75%, with an absolute gate at
+// -70 LUFS and a relative gate 10 LU below the gated mean.
+const (
+	blockStep     = 100 // ms between block starts
+	blockSteps    = 4   // steps per 400ms block
+	absoluteGate  = -70.0
+	relativeGate  = -10.0
+	loudnessShift = -0.691 // calibration offset of the K-weighting
+
+	// normalizeLookahead is the audio a Normalizer measures before it lets
+	// any through
+	normalizeLookahead = 10 // blocks of blockStep, i.e. 1s
+	// maxNormalizeGain caps the boost applied to quiet audio (+20 dB)
+	maxNormalizeGain = 10.0
+	// normalizeCeiling keeps boosted peaks below -1 dBFS
+	normalizeCeiling = 0.891
+)
+
+// biquad is a second-order IIR filter section
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     float64
+}
+
+func (f *biquad) process(x float64) float64 {
+	y := f.b0*x + f.b1*f.x1 + f.b2*f.x2 - f.a1*f.y1 - f.a2*f.y2
+	f.x2, f.x1 = f.x1, x
+	f.y2, f.y1 = f.y1, y
+	return y
+}
+
+// kWeighting returns the BS.1770 pre-filter (a high shelf modelling the
+// head) and RLB high-pass, designed for the sample rate
+func kWeighting(sampleRate int) [2]biquad {
+	fs := float64(sampleRate)
+
+	const shelfFreq, shelfGain, shelfQ = 1681.974450955533, 3.999843853973347, 0.7071752369554196
+	k := math.Tan(math.Pi * shelfFreq / fs)
+	vh := math.Pow(10, shelfGain/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + k/shelfQ + k*k
+	shelf := biquad{
+		b0: (vh + vb*k/shelfQ + k*k) / a0,
+		b1: 2 * (k*k - vh) / a0,
+		b2: (vh - vb*k/shelfQ + k*k) / a0,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/shelfQ + k*k) / a0,
+	}
+
+	const hpFreq, hpQ = 38.13547087602444, 0.5003270373238773
+	k = math.Tan(math.Pi * hpFreq / fs)
+	a0 = 1 + k/hpQ + k*k
+	highPass := biquad{
+		b0: 1, b1: -2, b2: 1,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/hpQ + k*k) / a0,
+	}
+	return [2]biquad{shelf, highPass}
+}
+
+// loudnessMeter measures integrated loudness of 16-bit mono PCM as it
+// arrives
+type loudnessMeter struct {
+	filters [2]biquad
+	stepLen int       // samples per step
+	sum     float64   // K-weighted sum of squares in the current step
+	n       int       // samples in the current step
+	steps   []float64 // mean squares of the last blockSteps steps
+	blocks  []float64 // mean square of every complete block
+	total   float64   // sum of squares of everything, for audio too short for a block
+	samples int
+}
+
+func newLoudnessMeter(sampleRate int) *loudnessMeter {
+	return &loudnessMeter{filters: kWeighting(sampleRate), stepLen: sampleRate * blockStep / 1000}
+}
+
+func (m *loudnessMeter) add(s float64) {
+	y := m.filters[1].process(m.filters[0].process(s))
+	m.sum += y * y
+	m.total += y * y
+	m.samples++
+	if m.n++; m.n < m.stepLen {
+		return
+	}
+	m.steps = append(m.steps, m.sum/float64(m.n))
+	m.sum, m.n = 0, 0
+	if len(m.steps) > blockSteps {
+		m.steps = m.steps[1:]
+	}
+	if len(m.steps) == blockSteps {
+		var block float64
+		for _, p := range m.steps {
+			block += p
+		}
+		m.blocks = append(m.blocks, block/blockSteps)
+	}
+}
+
+// integrated returns the gated loudness in LUFS so far, -Inf for silence.
+// Audio shorter than one block is measured as a whole.
+func (m *loudnessMeter) integrated() float64 {
+	if len(m.blocks) == 0 {
+		if m.samples == 0 {
+			return math.Inf(-1)
+		}
+		return powerToLUFS(m.total / float64(m.samples))
+	}
+
+	mean := func(gate float64) float64 {
+		var sum float64
+		var n int
+		for _, p := range m.blocks {
+			if powerToLUFS(p) > gate {
+				sum += p
+				n++
+			}
+		}
+		if n == 0 {
+			return 0
+		}
+		return sum / float64(n)
+	}
+	abs := mean(absoluteGate)
+	if abs == 0 {
+		return math.Inf(-1)
+	}
+	return powerToLUFS(mean(powerToLUFS(abs) + relativeGate))
+}
+
+func powerToLUFS(p float64) float64 {
+	return loudnessShift + 10*math.Log10(p)
+}
+
+// Loudness returns the integrated loudness of 16-bit mono PCM in LUFS, or
+// -Inf if it is silent
+func Loudness(pcm []byte, sampleRate int) float64 {
+	m := newLoudnessMeter(sampleRate)
+	for i := 0; i+1 < len(pcm); i += 2 {
+		m.add(float64(int16(binary.LittleEndian.Uint16(pcm[i:]))) / 32768)
+	}
+	return m.integrated()
+}
+
+// Normalize returns 16-bit mono PCM scaled to the target loudness in LUFS,
+// with the same gain limits as Normalizer
+func Normalize(pcm []byte, sampleRate int, target float64) []byte {
+	var out []byte
+	n := NewNormalizer(sampleRate, target, func(p []byte) { out = append(out, p...) })
+	n.lookahead = len(pcm) // measure it all before applying any gain
+	n.Write(pcm)
+	n.Flush()
+	return out
+}
+
+// Normalizer scales streamed 16-bit mono PCM to a target loudness. It holds
+// back the first second to measure it, then follows the loudness of all the
+// audio so far, ramping gain changes across each chunk so they don't click.
+// Boost is capped at +20 dB and peaks are kept below -1 dBFS: each write is
+// held back until the next, so a ramp already ends at a gain that suits the
+// audio after it.
+type Normalizer struct {
+	target    float64
+	meter     *loudnessMeter
+	out       func([]byte)
+	lookahead int    // bytes to hold back before the first output
+	pending   []byte // held back audio, and any odd trailing byte
+	started   bool
+	gain      float64
+}
+
+// NewNormalizer passes PCM written to it on to out, normalized to target
+// LUFS. Call Flush once the audio ends.
+func NewNormalizer(sampleRate int, target float64, out func([]byte)) *Normalizer {
+	return &Normalizer{
+		target:    target,
+		meter:     newLoudnessMeter(sampleRate),
+		out:       out,
+		lookahead: sampleRate * 2 * blockStep * normalizeLookahead / 1000,
+		gain:      1,
+	}
+}
+
+// Write measures pcm and passes it on once the lookahead is full
+func (n *Normalizer) Write(pcm []byte) {
+	measured := len(n.pending) &^ 1
+	n.pending = append(n.pending, pcm...)
+	for i := measured; i+1 < len(n.pending); i += 2 {
+		n.meter.add(float64(int16(binary.LittleEndian.Uint16(n.pending[i:]))) / 32768)
+	}
+	if !n.started && len(n.pending) < n.lookahead {
+		return
+	}
+	n.emit(len(pcm))
+}
+
+// Flush passes on the audio still held back
+func (n *Normalizer) Flush() {
+	n.emit(0)
+}
+
+// emit applies gain to the whole samples in pending except the last hold
+// bytes, and sends them on. The gain is limited by the peak of everything
+// pending, so the next ramp can start from it without clipping.
+func (n *Normalizer) emit(hold int) {
+	size := max(len(n.pending)-hold, 0) &^ 1
+	if size == 0 {
+		return
+	}
+	chunk := n.pending[:size]
+
+	var peak float64
+	for i := 0; i+1 < len(n.pending); i += 2 {
+		peak = max(peak, math.Abs(float64(int16(binary.LittleEndian.Uint16(n.pending[i:])))/32768))
+	}
+	gain := n.gain
+	if lufs := n.meter.integrated(); !math.IsInf(lufs, -1) {
+		gain = min(math.Pow(10, (n.target-lufs)/20), maxNormalizeGain)
+	}
+	if peak > 0 {
+		gain = min(gain, normalizeCeiling/peak)
+	}
+
+	// The first chunk takes the measured gain outright; later ones ramp
+	// from the previous gain
+	from := gain
+	if n.started {
+		from = n.gain
+	}
+	out := make([]byte, size)
+	samples := size / 2
+	for i := range samples {
+		g := from + (gain-from)*float64(i+1)/float64(samples)
+		s := float64(int16(binary.LittleEndian.Uint16(chunk[i*2:]))) * g
+		binary.LittleEndian.PutUint16(out[i*2:], uint16(int16(max(min(s, math.MaxInt16), math.MinInt16))))
+	}
+	n.gain = gain
+	n.started = true
+	n.pending = append(n.pending[:0], n.pending[size:]...)
+	n.out(out)
+}
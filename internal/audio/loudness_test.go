@@ -0,0 +1,91 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestLoudnessTone(t *testing.T) {
+	// BS.1770 is calibrated so a 1 kHz sine peaking at 0 dBFS measures
+	// -3.01 LUFS; at -20 dBFS it is -23.01 LUFS
+	for _, rate := range []int{16000, 24000, 48000} {
+		for _, tt := range []struct{ amp, want float64 }{{1, -3.01}, {0.1, -23.01}} {
+			got := Loudness(tone(1000, tt.amp, 3*time.Second, rate), rate)
+			if math.Abs(got-tt.want) > 0.1 {
+				t.Errorf("%d Hz, amplitude %g: %.2f LUFS, want %.2f", rate, tt.amp, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestLoudnessGating(t *testing.T) {
+	const rate = 24000
+	if got := Loudness(silence(time.Second, rate), rate); !math.IsInf(got, -1) {
+		t.Errorf("silence measures %.2f LUFS, want -Inf", got)
+	}
+	// Pauses fall under the gates, so they don't pull speech down
+	speech := tone(1000, 0.1, 10*time.Second, rate)
+	withPauses := concat(silence(2*time.Second, rate), speech, silence(2*time.Second, rate))
+	if a, b := Loudness(speech, rate), Loudness(withPauses, rate); math.Abs(a-b) > 0.5 {
+		t.Errorf("pauses moved loudness from %.2f to %.2f LUFS", a, b)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	const rate = 24000
+	out := Normalize(tone(1000, 0.05, 3*time.Second, rate), rate, -16)
+	if got := Loudness(out, rate); math.Abs(got-(-16)) > 0.2 {
+		t.Errorf("normalized to %.2f LUFS, want -16", got)
+	}
+}
+
+// samples decodes 16-bit PCM
+func samples(pcm []byte) []float64 {
+	out := make([]float64, len(pcm)/2)
+	for i := range out {
+		out[i] = float64(int16(binary.LittleEndian.Uint16(pcm[2*i:])))
+	}
+	return out
+}
+
+func TestNormalizerStream(t *testing.T) {
+	const rate = 24000
+	// Quiet speech that gets boosted, then a loud passage the boost must
+	// not push past the ceiling
+	in := concat(
+		tone(1000, 0.05, 2*time.Second, rate),
+		tone(1000, 0.8, 2*time.Second, rate),
+		tone(1000, 0.05, 2*time.Second, rate),
+	)
+	var out []byte
+	n := NewNormalizer(rate, -16, func(p []byte) { out = append(out, p...) })
+	const chunk = rate * 2 / 10 // 100ms
+	for i := 0; i < len(in); i += chunk {
+		n.Write(in[i:min(i+chunk, len(in))])
+	}
+	n.Flush()
+	if len(out) != len(in) {
+		t.Fatalf("got %d bytes out for %d in", len(out), len(in))
+	}
+
+	x, y := samples(in), samples(out)
+	ceiling := normalizeCeiling * 32768
+	prev := -1.0
+	for i := range y {
+		if math.Abs(y[i]) > ceiling+1 {
+			t.Fatalf("sample %d is %.0f, over the %.0f ceiling", i, y[i], ceiling)
+		}
+		// Gain is only measurable where the input is well above the
+		// rounding error
+		if math.Abs(x[i]) < 1000 {
+			continue
+		}
+		g := y[i] / x[i]
+		if prev >= 0 && math.Abs(g-prev) > 0.01*max(g, prev, 1) {
+			t.Fatalf("gain jumps from %.3f to %.3f at sample %d (%s)", prev, g, i, time.Duration(i)*time.Second/rate)
+		}
+		prev = g
+	}
+}
@@ -0,0 +1,238 @@
+// Package ogg reads and writes single-stream Ogg containers (RFC 3533)
+package ogg
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Page header flags
+const (
+	flagContinued = 0x01
+	flagBOS       = 0x02
+	flagEOS       = 0x04
+)
+
+const (
+	headerLen = 27
+	// pageTarget is the body size at which a page is closed; 4-8KB is what
+	// most muxers use
+	pageTarget = 4096
+)
+
+// ErrNotOgg is returned when data doesn't start with an Ogg page
+var ErrNotOgg = errors.New("not an Ogg stream")
+
+// Writer packs packets into the pages of one logical stream
+type Writer struct {
+	w       io.Writer
+	serial  uint32
+	seq     uint32
+	lacing  []byte
+	body    []byte
+	granule int64 // granule of the last packet finished on the pending page
+	last    int64 // granule of the last packet written
+	cont    bool  // pending page starts with the tail of a packet
+	started bool
+}
+
+// NewWriter starts a stream with the given serial number
+func NewWriter(w io.Writer, serial uint32) *Writer {
+	return &Writer{w: w, serial: serial, granule: -1}
+}
+
+// WritePacket appends one packet. granule is the codec-defined position at
+// the end of the packet (for audio, usually the sample count so far).
+func (w *Writer) WritePacket(p []byte, granule int64) error {
+	for {
+		if len(w.lacing) == 255 {
+			if err := w.flush(0); err != nil {
+				return err
+			}
+		}
+		n := min(len(p), 255)
+		w.lacing = append(w.lacing, byte(n))
+		w.body = append(w.body, p[:n]...)
+		p = p[n:]
+		if n < 255 {
+			break
+		}
+	}
+	w.granule = granule
+	w.last = granule
+	if len(w.body) >= pageTarget {
+		return w.flush(0)
+	}
+	return nil
+}
+
+// Flush ends the current page, so the next packet starts a new one. Codecs
+// use this to keep header packets on their own pages.
+func (w *Writer) Flush() error {
+	if len(w.lacing) == 0 {
+		return nil
+	}
+	return w.flush(0)
+}
+
+// Close writes the final page, marked end-of-stream
+func (w *Writer) Close() error {
+	if len(w.lacing) == 0 {
+		w.granule = w.last
+	}
+	return w.flush(flagEOS)
+}
+
+func (w *Writer) flush(flags byte) error {
+	if w.cont {
+		flags |= flagContinued
+	}
+	if !w.started {
+		flags |= flagBOS
+		w.started = true
+	}
+
+	page := make([]byte, headerLen, headerLen+len(w.lacing)+len(w.body))
+	copy(page, "OggS")
+	page[5] = flags
+	binary.LittleEndian.PutUint64(page[6:], uint64(w.granule))
+	binary.LittleEndian.PutUint32(page[14:], w.serial)
+	binary.LittleEndian.PutUint32(page[18:], w.seq)
+	page[26] = byte(len(w.lacing))
+	page = append(page, w.lacing...)
+	page = append(page, w.body...)
+	binary.LittleEndian.PutUint32(page[22:], crc(page))
+
+	// A final lacing value of 255 means the packet goes on in the next page
+	w.cont = len(w.lacing) > 0 && w.lacing[len(w.lacing)-1] == 255
+	w.seq++
+	w.lacing = w.lacing[:0]
+	w.body = w.body[:0]
+	w.granule = -1
+
+	_, err := w.w.Write(page)
+	return err
+}
+
+// Reader returns the packets of the first logical stream in an Ogg file
+type Reader struct {
+	r       *bufio.Reader
+	serial  uint32
+	started bool
+	packets [][]byte
+	partial []byte
+	eos     bool
+}
+
+// NewReader reads pages from r
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// ReadPacket returns the next packet, or io.EOF after the last one
+func (r *Reader) ReadPacket() ([]byte, error) {
+	for len(r.packets) == 0 {
+		if r.eos {
+			return nil, io.EOF
+		}
+		if err := r.readPage(); err != nil {
+			return nil, err
+		}
+	}
+	p := r.packets[0]
+	r.packets = r.packets[1:]
+	return p, nil
+}
+
+func (r *Reader) readPage() error {
+	header := make([]byte, headerLen)
+	if _, err := io.ReadFull(r.r, header); err != nil {
+		if err == io.EOF && r.started {
+			r.eos = true // stream ended without an EOS page
+			return nil
+		}
+		if !r.started {
+			return ErrNotOgg
+		}
+		return fmt.Errorf("ogg: %w", err)
+	}
+	if string(header[0:4]) != "OggS" || header[4] != 0 {
+		if !r.started {
+			return ErrNotOgg
+		}
+		return fmt.Errorf("ogg: lost page sync")
+	}
+
+	lacing := make([]byte, header[26])
+	if _, err := io.ReadFull(r.r, lacing); err != nil {
+		return fmt.Errorf("ogg: %w", err)
+	}
+	size := 0
+	for _, l := range lacing {
+		size += int(l)
+	}
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r.r, body); err != nil {
+		return fmt.Errorf("ogg: %w", err)
+	}
+
+	want := binary.LittleEndian.Uint32(header[22:26])
+	binary.LittleEndian.PutUint32(header[22:26], 0)
+	page := append(append(header, lacing...), body...)
+	if crc(page) != want {
+		return fmt.Errorf("ogg: page checksum mismatch")
+	}
+
+	flags := header[5]
+	serial := binary.LittleEndian.Uint32(header[14:18])
+	if !r.started {
+		r.serial = serial
+		r.started = true
+	} else if serial != r.serial {
+		return nil // another multiplexed stream; not ours
+	}
+	if flags&flagContinued == 0 {
+		r.partial = nil
+	}
+
+	for _, l := range lacing {
+		r.partial = append(r.partial, body[:l]...)
+		body = body[l:]
+		if l < 255 {
+			r.packets = append(r.packets, r.partial)
+			r.partial = nil
+		}
+	}
+	if flags&flagEOS != 0 {
+		r.eos = true
+	}
+	return nil
+}
+
+var crcTable = func() [256]uint32 {
+	var t [256]uint32
+	for i := range t {
+		c := uint32(i) << 24
+		for range 8 {
+			if c&0x80000000 != 0 {
+				c = c<<1 ^ 0x04c11db7
+			} else {
+				c <<= 1
+			}
+		}
+		t[i] = c
+	}
+	return t
+}()
+
+// crc is the Ogg page checksum: CRC-32, polynomial 0x04c11db7, not reflected
+func crc(b []byte) uint32 {
+	var c uint32
+	for _, v := range b {
+		c = c<<8 ^ crcTable[byte(c>>24)^v]
+	}
+	return c
+}
@@ -0,0 +1,123 @@
+package audio
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gen2brain/malgo"
+)
+
+// deviceOutput plays through a specific output device with malgo, for when
+// the user picks one (oto always uses the system default)
+type deviceOutput struct {
+	ctx    *malgo.AllocatedContext
+	device *malgo.Device
+
+	mu      sync.Mutex
+	buf     []byte
+	played  int64 // bytes handed to the device
+	paused  bool
+	closed  bool
+	stopped bool
+	drained chan struct{}
+}
+
+func openDeviceOutput(name string) (*deviceOutput, error) {
+	ctx, err := malgo.InitContext(nil, malgo.ContextConfig{}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("init audio: %w", err)
+	}
+	id, err := deviceID(ctx.Context, malgo.Playback, name)
+	if err != nil {
+		ctx.Free()
+		return nil, fmt.Errorf("output device: %w", err)
+	}
+
+	o := &deviceOutput{ctx: ctx, drained: make(chan struct{})}
+	cfg := malgo.DefaultDeviceConfig(malgo.Playback)
+	cfg.Playback.Format = malgo.FormatS16
+	cfg.Playback.Channels = ChannelCount
+	cfg.SampleRate = SampleRate
+	cfg.PeriodSizeInMilliseconds = uint32(deviceLatency / time.Millisecond / 4)
+	cfg.Periods = 4
+	if id != nil {
+		cfg.Playback.DeviceID = id.Pointer()
+	}
+	o.device, err = malgo.InitDevice(ctx.Context, cfg, malgo.DeviceCallbacks{Data: o.fill})
+	if err != nil {
+		ctx.Free()
+		return nil, fmt.Errorf("open output device: %w", err)
+	}
+	if err := o.device.Start(); err != nil {
+		o.device.Uninit()
+		ctx.Free()
+		return nil, fmt.Errorf("start output device: %w", err)
+	}
+	return o, nil
+}
+
+// fill runs on the audio thread: hand over queued PCM, silence if there is
+// none yet
+func (o *deviceOutput) fill(out, _ []byte, _ uint32) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	n := 0
+	if !o.paused {
+		n = copy(out, o.buf)
+		o.buf = o.buf[n:]
+		o.played += int64(n)
+	}
+	clear(out[n:])
+	if o.closed && len(o.buf) == 0 && o.drained != nil {
+		close(o.drained)
+		o.drained = nil
+	}
+}
+
+func (o *deviceOutput) write(pcm []byte) {
+	o.mu.Lock()
+	if !o.stopped {
+		o.buf = append(o.buf, pcm...)
+	}
+	o.mu.Unlock()
+}
+
+func (o *deviceOutput) playedBytes() int64 {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.played
+}
+
+func (o *deviceOutput) setPaused(paused bool) {
+	o.mu.Lock()
+	o.paused = paused
+	o.mu.Unlock()
+}
+
+// stop drops queued audio so the device falls silent on its next callback
+func (o *deviceOutput) stop() {
+	o.mu.Lock()
+	o.stopped = true
+	o.buf = nil
+	o.paused = false
+	o.mu.Unlock()
+}
+
+// close waits until every queued sample has been handed to the device, then
+// lets the device play out its own buffer before shutting it down
+func (o *deviceOutput) close() {
+	o.mu.Lock()
+	o.closed = true
+	drained := o.drained
+	stopped := o.stopped
+	o.mu.Unlock()
+	if drained != nil {
+		<-drained
+	}
+	if !stopped {
+		time.Sleep(deviceLatency)
+	}
+	o.device.Uninit()
+	o.ctx.Free()
+}
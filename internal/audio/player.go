@@ -1,8 +1,11 @@
 package audio
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ebitengine/oto/v3"
@@ -11,6 +14,13 @@ import (
 const (
 	SampleRate   = 24000
 	ChannelCount = 1
+
+	bytesPerSecond = SampleRate * ChannelCount * 2
+	// deviceLatency is the driver buffer we ask for: audio that has left
+	// our buffers is heard this much later
+	deviceLatency = 100 * time.Millisecond
+	// progressInterval is how often OnProgress callbacks run
+	progressInterval = 100 * time.Millisecond
 )
 
 // StreamPlayer plays PCM audio chunks as they arrive
@@ -19,79 +29,228 @@ type StreamPlayer struct {
 	player *oto.Player
 	pw     *io.PipeWriter
 	pr     *io.PipeReader
-	done   chan struct{}
+	done   chan struct{} // closed by Stop
 	once   sync.Once
+	paused atomic.Bool
+	out    *deviceOutput // set when playing to a chosen device
+
+	written  atomic.Int64  // bytes passed to Write
+	consumed atomic.Int64  // bytes oto has read from the pipe
+	finished chan struct{} // closed when playback ends, stops progress reports
+	finish   sync.Once
+	progress sync.WaitGroup
+}
+
+// countingReader tallies the bytes oto pulls from the pipe
+type countingReader struct {
+	r io.Reader
+	n *atomic.Int64
 }
 
+func (c countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n.Add(int64(n))
+	return n, err
+}
+
+// errStopped unblocks a Write waiting on a stopped player
+var errStopped = errors.New("playback stopped")
+
 var (
 	otoCtx     *oto.Context
+	otoErr     error
 	otoCtxOnce sync.Once
 )
 
-func getOtoContext() *oto.Context {
+// getOtoContext opens the default output once. Failure (no sound device,
+// e.g. a headless server) is remembered and returned on every call.
+func getOtoContext() (*oto.Context, error) {
 	otoCtxOnce.Do(func() {
 		op := &oto.NewContextOptions{
 			SampleRate:   SampleRate,
 			ChannelCount: ChannelCount,
 			Format:       oto.FormatSignedInt16LE,
+			BufferSize:   deviceLatency,
 		}
 		var ready chan struct{}
-		var err error
-		otoCtx, ready, err = oto.NewContext(op)
-		if err != nil {
-			panic("oto init: " + err.Error())
+		otoCtx, ready, otoErr = oto.NewContext(op)
+		if otoErr != nil {
+			otoErr = fmt.Errorf("open audio output: %w", otoErr)
+			return
 		}
 		<-ready
+		if err := otoCtx.Err(); err != nil {
+			otoErr = fmt.Errorf("open audio output: %w", err)
+		}
 	})
-	return otoCtx
+	return otoCtx, otoErr
 }
 
-// NewStreamPlayer creates a player that accepts PCM chunks via Write
-func NewStreamPlayer() *StreamPlayer {
+// NewStreamPlayer creates a player that accepts PCM chunks via Write. It
+// plays to the named output device (see FindDevice), or the system default
+// if device is empty.
+func NewStreamPlayer(device string) (*StreamPlayer, error) {
+	if device != "" {
+		out, err := openDeviceOutput(device)
+		if err != nil {
+			return nil, err
+		}
+		return &StreamPlayer{out: out, done: make(chan struct{}), finished: make(chan struct{})}, nil
+	}
+
+	ctx, err := getOtoContext()
+	if err != nil {
+		return nil, err
+	}
 	pr, pw := io.Pipe()
-	ctx := getOtoContext()
-	player := ctx.NewPlayer(pr)
 
 	sp := &StreamPlayer{
-		ctx:    ctx,
-		player: player,
-		pw:     pw,
-		pr:     pr,
-		done:   make(chan struct{}),
+		ctx:      ctx,
+		pw:       pw,
+		pr:       pr,
+		done:     make(chan struct{}),
+		finished: make(chan struct{}),
 	}
+	sp.player = ctx.NewPlayer(countingReader{pr, &sp.consumed})
+	sp.player.Play()
 
-	player.Play()
-
-	return sp
+	return sp, nil
 }
 
 // Write sends PCM data to the player. Safe to call from any goroutine.
+// Audio written after Stop is discarded.
 func (sp *StreamPlayer) Write(pcm []byte) {
+	select {
+	case <-sp.done:
+		return
+	default:
+	}
+	// Counted up front: the pipe only returns once oto has read it all
+	sp.written.Add(int64(len(pcm)))
+	if sp.out != nil {
+		sp.out.write(pcm)
+		return
+	}
 	sp.pw.Write(pcm)
 }
 
-// Close signals end of audio data and waits for playback to fully drain
+// Pause holds playback; audio written meanwhile queues up
+func (sp *StreamPlayer) Pause() {
+	if sp.paused.Swap(true) {
+		return
+	}
+	if sp.out != nil {
+		sp.out.setPaused(true)
+		return
+	}
+	sp.player.Pause()
+}
+
+// Resume continues paused playback
+func (sp *StreamPlayer) Resume() {
+	if !sp.paused.Swap(false) {
+		return
+	}
+	if sp.out != nil {
+		sp.out.setPaused(false)
+		return
+	}
+	sp.player.Play()
+}
+
+// Paused reports whether playback is paused
+func (sp *StreamPlayer) Paused() bool {
+	return sp.paused.Load()
+}
+
+// Stop silences the player at once and drops queued audio. Pending and
+// later Writes return immediately, and Close doesn't wait for a drain.
+func (sp *StreamPlayer) Stop() {
+	sp.once.Do(func() {
+		close(sp.done)
+		if sp.out != nil {
+			sp.out.stop()
+			return
+		}
+		sp.player.Pause()
+		sp.pr.CloseWithError(errStopped)
+	})
+	sp.end()
+}
+
+// end stops progress reports, waiting for a callback in flight so none
+// lands after Close or Stop returns
+func (sp *StreamPlayer) end() {
+	sp.finish.Do(func() { close(sp.finished) })
+	sp.progress.Wait()
+}
+
+// Stopped is closed once Stop has been called
+func (sp *StreamPlayer) Stopped() <-chan struct{} {
+	return sp.done
+}
+
+// Position returns how much of the written audio has been heard
+func (sp *StreamPlayer) Position() time.Duration {
+	var played int64
+	if sp.out != nil {
+		played = sp.out.playedBytes()
+	} else {
+		played = sp.consumed.Load() - int64(sp.player.BufferedSize())
+	}
+	played -= int64(deviceLatency) * bytesPerSecond / int64(time.Second)
+	return bytesToDuration(int(min(max(played, 0), sp.written.Load())), bytesPerSecond)
+}
+
+// Duration returns how much audio has been written so far
+func (sp *StreamPlayer) Duration() time.Duration {
+	return bytesToDuration(int(sp.written.Load()), bytesPerSecond)
+}
+
+// OnProgress calls fn with Position and Duration every 100ms, from its own
+// goroutine, until Close or Stop returns
+func (sp *StreamPlayer) OnProgress(fn func(position, duration time.Duration)) {
+	sp.progress.Add(1)
+	go func() {
+		defer sp.progress.Done()
+		t := time.NewTicker(progressInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				fn(sp.Position(), sp.Duration())
+			case <-sp.finished:
+				return
+			}
+		}
+	}()
+}
+
+// Close signals end of audio data and waits until the last sample has been
+// played: oto (or the device callback) has taken everything written, its
+// buffer is empty, and the driver's buffer has had time to play out
 func (sp *StreamPlayer) Close() {
+	defer sp.end()
+	if sp.out != nil {
+		sp.out.close()
+		return
+	}
 	sp.pw.Close()
 
-	// Wait for oto player to finish — poll IsPlaying with a safety timeout.
-	deadline := time.After(30 * time.Second)
-	for {
+	for sp.paused.Load() || sp.consumed.Load() < sp.written.Load() || sp.player.BufferedSize() > 0 {
+		if sp.player.Err() != nil {
+			return
+		}
 		select {
-		case <-deadline:
+		case <-sp.done:
 			return
-		default:
-			if !sp.player.IsPlaying() {
-				// oto may report not-playing while audio hardware still has
-				// samples in flight. Wait based on oto's internal buffer size.
-				// Default buffer is 8192 bytes. At 24kHz 16-bit mono = 48000 bytes/s,
-				// that's ~170ms. Add generous margin for OS audio pipeline.
-				time.Sleep(500 * time.Millisecond)
-				return
-			}
-			time.Sleep(10 * time.Millisecond)
+		case <-time.After(5 * time.Millisecond):
 		}
 	}
+	select {
+	case <-time.After(deviceLatency):
+	case <-sp.done:
+	}
 }
 
 // AllPCM collects all written PCM bytes (for caching). Must be used via WriteTee.
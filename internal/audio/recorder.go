@@ -1,44 +1,100 @@
 package audio
 
 import (
+	"fmt"
+	"io"
 	"sync"
+	"time"
 
 	"github.com/gen2brain/malgo"
 )
 
-// Recorder captures audio from the default input device
+// DefaultRecordBuffer is how much unread audio a Recorder holds unless
+// Buffer says otherwise
+const DefaultRecordBuffer = 5 * time.Minute
+
+// Recorder captures audio from an input device. Captured PCM is read from
+// it as an io.Reader while recording goes on; unread audio waits in a
+// fixed-size buffer, so memory stays bounded however long capture runs.
 type Recorder struct {
 	ctx        *malgo.AllocatedContext
 	device     *malgo.Device
+	deviceID   *malgo.DeviceID // nil for the system default
 	sampleRate uint32
 	channels   uint32
-	mu         sync.Mutex
-	buf        []byte
+	buffer     time.Duration
+	overflow   Overflow
+	ring       *ringBuffer
+	closeOnce  sync.Once
+	onData     func([]byte)
+	onLevel    func(Level)
 }
 
-func NewRecorder(sampleRate, channels int) (*Recorder, error) {
+// NewRecorder prepares capture from the named input device (see FindDevice),
+// or the system default if device is empty
+func NewRecorder(sampleRate, channels int, device string) (*Recorder, error) {
 	ctx, err := malgo.InitContext(nil, malgo.ContextConfig{}, nil)
 	if err != nil {
 		return nil, err
 	}
+	id, err := deviceID(ctx.Context, malgo.Capture, device)
+	if err != nil {
+		ctx.Free()
+		return nil, fmt.Errorf("input device: %w", err)
+	}
 
 	return &Recorder{
 		ctx:        ctx,
+		deviceID:   id,
 		sampleRate: uint32(sampleRate),
 		channels:   uint32(channels),
+		buffer:     DefaultRecordBuffer,
+		overflow:   DropOldest,
 	}, nil
 }
 
+// Buffer sets how much unread audio is held and what gives when the reader
+// falls further behind than that. Must be called before Start.
+func (r *Recorder) Buffer(d time.Duration, overflow Overflow) {
+	r.buffer = d
+	r.overflow = overflow
+}
+
+// OnData registers a callback that receives a copy of every captured block
+// as it arrives, in addition to the buffer Read drains. Must be called
+// before Start. The callback runs on the audio thread and must not block.
+func (r *Recorder) OnData(fn func([]byte)) {
+	r.onData = fn
+}
+
+// OnLevel registers a callback that receives the RMS and peak level of
+// every captured block. Must be called before Start. The callback runs on
+// the audio thread and must not block.
+func (r *Recorder) OnLevel(fn func(Level)) {
+	r.onLevel = fn
+}
+
 func (r *Recorder) Start() error {
 	deviceConfig := malgo.DefaultDeviceConfig(malgo.Capture)
 	deviceConfig.Capture.Format = malgo.FormatS16
 	deviceConfig.Capture.Channels = r.channels
 	deviceConfig.SampleRate = r.sampleRate
+	if r.deviceID != nil {
+		deviceConfig.Capture.DeviceID = r.deviceID.Pointer()
+	}
+
+	frame := int(r.channels) * 2
+	bytesPerSec := int(r.sampleRate) * frame
+	r.ring = newRingBuffer(int(r.buffer.Seconds()*float64(bytesPerSec)), frame, r.overflow)
 
 	onData := func(outputSamples, inputSamples []byte, frameCount uint32) {
-		r.mu.Lock()
-		r.buf = append(r.buf, inputSamples...)
-		r.mu.Unlock()
+		r.ring.write(inputSamples)
+		if r.onData != nil {
+			r.onData(append([]byte(nil), inputSamples...))
+		}
+		if r.onLevel != nil {
+			r.onLevel(blockLevel(inputSamples))
+		}
 	}
 
 	callbacks := malgo.DeviceCallbacks{
@@ -47,6 +103,7 @@ func (r *Recorder) Start() error {
 
 	device, err := malgo.InitDevice(r.ctx.Context, deviceConfig, callbacks)
 	if err != nil {
+		r.ring.close()
 		return err
 	}
 
@@ -54,17 +111,44 @@ func (r *Recorder) Start() error {
 	return device.Start()
 }
 
-// Stop ends recording and returns the captured PCM data
-func (r *Recorder) Stop() []byte {
-	if r.device != nil {
-		r.device.Stop()
-		r.device.Uninit()
+// Read returns captured 16-bit PCM as it arrives, blocking until there is
+// some. After Close it drains the buffer and then returns io.EOF.
+func (r *Recorder) Read(p []byte) (int, error) {
+	if r.ring == nil {
+		return 0, io.EOF
 	}
-	if r.ctx != nil {
-		r.ctx.Free()
+	return r.ring.Read(p)
+}
+
+// Dropped returns how much audio was lost because the buffer overflowed
+func (r *Recorder) Dropped() time.Duration {
+	if r.ring == nil {
+		return 0
 	}
+	return bytesToDuration(int(r.ring.droppedBytes()), int(r.sampleRate*r.channels)*2)
+}
+
+// Close ends recording and releases the device. Audio not yet read stays
+// readable.
+func (r *Recorder) Close() {
+	r.closeOnce.Do(func() {
+		if r.device != nil {
+			r.device.Stop()
+			r.device.Uninit()
+		}
+		if r.ctx != nil {
+			r.ctx.Free()
+		}
+		if r.ring != nil {
+			r.ring.close()
+		}
+	})
+}
 
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	return r.buf
+// Stop ends recording and returns the captured PCM not yet read. For short
+// recordings taken in one piece; it must not be mixed with a running Read.
+func (r *Recorder) Stop() []byte {
+	r.Close()
+	pcm, _ := io.ReadAll(r)
+	return pcm
 }
@@ -0,0 +1,107 @@
+package audio
+
+import (
+	"io"
+	"sync"
+)
+
+// Overflow decides what a full recording buffer gives up
+type Overflow int
+
+const (
+	// DropOldest overwrites the oldest unread audio, keeping the latest
+	DropOldest Overflow = iota
+	// DropNewest discards incoming blocks until there is room again
+	DropNewest
+)
+
+// ringBuffer is a bounded FIFO between the audio thread, which must never
+// block, and a reader that waits for data. Its storage is allocated up
+// front, so the audio thread never allocates; the OS only commits the pages
+// of a large bound as they are written.
+type ringBuffer struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	buf     []byte
+	start   int // read position
+	n       int // bytes buffered
+	policy  Overflow
+	dropped int64 // bytes lost to overflow
+	closed  bool
+}
+
+// newRingBuffer holds size bytes; frame is the size of one sample frame, so
+// overflow never splits a sample
+func newRingBuffer(size, frame int, policy Overflow) *ringBuffer {
+	size = max(size/frame, 1) * frame
+	b := &ringBuffer{buf: make([]byte, size), policy: policy}
+	b.cond = sync.NewCond(&b.mu)
+	return b
+}
+
+// write queues p without blocking, applying the overflow policy when it
+// doesn't fit. p must be whole frames.
+func (b *ringBuffer) write(p []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	size := len(b.buf)
+	if excess := b.n + len(p) - size; excess > 0 {
+		if b.policy == DropNewest {
+			b.dropped += int64(len(p))
+			return
+		}
+		if len(p) >= size {
+			b.dropped += int64(b.n + len(p) - size)
+			p = p[len(p)-size:]
+			b.start, b.n = 0, 0
+		} else {
+			b.dropped += int64(excess)
+			b.start = (b.start + excess) % size
+			b.n -= excess
+		}
+	}
+	end := (b.start + b.n) % size
+	copied := copy(b.buf[end:], p)
+	copy(b.buf, p[copied:])
+	b.n += len(p)
+	b.cond.Signal()
+}
+
+// Read waits for audio and returns what is buffered, up to len(p). It
+// returns io.EOF once the buffer is closed and drained.
+func (b *ringBuffer) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for b.n == 0 && !b.closed {
+		b.cond.Wait()
+	}
+	if b.n == 0 {
+		return 0, io.EOF
+	}
+	n := min(len(p), b.n)
+	copied := copy(p[:n], b.buf[b.start:])
+	copy(p[copied:n], b.buf)
+	b.start = (b.start + n) % len(b.buf)
+	b.n -= n
+	return n, nil
+}
+
+// close lets Read drain what is left and then return io.EOF
+func (b *ringBuffer) close() {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+	b.cond.Broadcast()
+}
+
+func (b *ringBuffer) droppedBytes() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.dropped
+}
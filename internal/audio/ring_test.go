@@ -0,0 +1,153 @@
+package audio
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+// counting returns n bytes of a repeating 0..250 sequence starting at from
+func counting(from, n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte((from + i) % 251)
+	}
+	return b
+}
+
+func TestRingOrder(t *testing.T) {
+	// Less than is written in total, so data wraps around, but more than is
+	// ever unread
+	const size = 256 << 10
+	r := newRingBuffer(size, 2, DropOldest)
+	var want []byte
+	var got []byte
+	buf := make([]byte, 8000)
+	for i := range 100 {
+		block := counting(len(want), 2*(500+i*37))
+		r.write(block)
+		want = append(want, block...)
+		if i%3 == 0 {
+			n, err := r.Read(buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, buf[:n]...)
+		}
+	}
+	r.close()
+	rest, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = append(got, rest...)
+	if r.droppedBytes() != 0 {
+		t.Fatalf("dropped %d bytes below capacity", r.droppedBytes())
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("read back %d bytes out of order (wrote %d)", len(got), len(want))
+	}
+}
+
+func TestRingOverflow(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy Overflow
+		keep   func(all []byte) []byte
+	}{
+		{"drop oldest", DropOldest, func(all []byte) []byte { return all[len(all)-1000:] }},
+		{"drop newest", DropNewest, func(all []byte) []byte { return all[:1000] }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRingBuffer(1000, 2, tt.policy)
+			var all []byte
+			for range 30 {
+				block := counting(len(all), 100)
+				r.write(block)
+				all = append(all, block...)
+			}
+			r.close()
+			got, _ := io.ReadAll(r)
+			if !bytes.Equal(got, tt.keep(all)) {
+				t.Errorf("kept %d bytes, not the expected 1000", len(got))
+			}
+			if d := r.droppedBytes(); d != int64(len(all)-1000) {
+				t.Errorf("dropped %d bytes, want %d", d, len(all)-1000)
+			}
+		})
+	}
+}
+
+func TestRingOversizedWrite(t *testing.T) {
+	r := newRingBuffer(100, 2, DropOldest)
+	r.write(counting(0, 40))
+	r.write(counting(40, 300)) // larger than the whole buffer
+	r.close()
+	got, _ := io.ReadAll(r)
+	if !bytes.Equal(got, counting(240, 100)) {
+		t.Errorf("kept %v", got)
+	}
+	if r.droppedBytes() != 240 {
+		t.Errorf("dropped %d bytes, want 240", r.droppedBytes())
+	}
+}
+
+func TestRingFrames(t *testing.T) {
+	// 1001 bytes of 4-byte frames holds 250 frames, so dropping never
+	// splits one
+	r := newRingBuffer(1001, 4, DropOldest)
+	for range 10 {
+		r.write(make([]byte, 160))
+	}
+	if d := r.droppedBytes(); d%4 != 0 || d != 1600-1000 {
+		t.Errorf("dropped %d bytes", d)
+	}
+}
+
+func TestRingBlockingRead(t *testing.T) {
+	r := newRingBuffer(1000, 2, DropOldest)
+	done := make(chan []byte)
+	go func() {
+		got, _ := io.ReadAll(r)
+		done <- got
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("ReadAll returned before the buffer was closed")
+	case <-time.After(20 * time.Millisecond):
+	}
+	r.write(counting(0, 10))
+	r.write(counting(10, 10))
+	r.close()
+	r.write(counting(20, 10)) // ignored after close
+
+	select {
+	case got := <-done:
+		if !bytes.Equal(got, counting(0, 20)) {
+			t.Errorf("read %v", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Read didn't return io.EOF after close")
+	}
+}
+
+func TestRingConcurrent(t *testing.T) {
+	r := newRingBuffer(1<<20, 2, DropOldest)
+	const blocks, blockSize = 2000, 320
+	go func() {
+		for i := range blocks {
+			r.write(counting(i*blockSize, blockSize))
+		}
+		r.close()
+	}()
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, counting(0, blocks*blockSize)) {
+		t.Errorf("read %d bytes, want %d in order", len(got), blocks*blockSize)
+	}
+}
@@ -0,0 +1,139 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+	"slices"
+	"time"
+)
+
+// Chunk is a piece of a longer recording
+type Chunk struct {
+	Start time.Duration
+	PCM   []byte
+}
+
+// frameDuration is the analysis window for energy measurements
+const frameDuration = 20 * time.Millisecond
+
+// FrameRMS returns the RMS level (0-1) of each 20ms frame of 16-bit mono PCM
+func FrameRMS(pcm []byte, sampleRate int) []float64 {
+	frameLen := sampleRate * int(frameDuration/time.Millisecond) / 1000
+	if frameLen == 0 {
+		return nil
+	}
+	n := len(pcm) / 2 / frameLen
+	out := make([]float64, n)
+	for f := range n {
+		var sum float64
+		for i := range frameLen {
+			s := float64(int16(binary.LittleEndian.Uint16(pcm[(f*frameLen+i)*2:]))) / 32768
+			sum += s * s
+		}
+		out[f] = math.Sqrt(sum / float64(frameLen))
+	}
+	return out
+}
+
+// SplitAtSilence cuts 16-bit mono PCM into chunks no longer than maxLen.
+// Each cut is placed at the quietest 200ms stretch in the second half of the
+// window, so words aren't split unless the speaker never pauses.
+func SplitAtSilence(pcm []byte, sampleRate int, maxLen time.Duration) []Chunk {
+	bytesPerSec := sampleRate * 2
+	if bytesPerSec == 0 || maxLen <= 0 {
+		return []Chunk{{PCM: pcm}}
+	}
+
+	rms := FrameRMS(pcm, sampleRate)
+	frameBytes := sampleRate * int(frameDuration/time.Millisecond) / 1000 * 2
+	// A limit under one frame still has to make progress
+	maxFrames := max(int(maxLen/frameDuration), 1)
+	const smooth = 10 // frames averaged when looking for a pause (200ms)
+
+	var chunks []Chunk
+	startFrame := 0
+	for {
+		start := startFrame * frameBytes
+		if len(rms)-startFrame <= maxFrames {
+			chunks = append(chunks, Chunk{Start: bytesToDuration(start, bytesPerSec), PCM: pcm[start:]})
+			return chunks
+		}
+
+		best, bestLevel := startFrame+maxFrames, math.Inf(1)
+		for f := startFrame + maxFrames/2; f+smooth <= startFrame+maxFrames; f++ {
+			var level float64
+			for _, v := range rms[f : f+smooth] {
+				level += v
+			}
+			if level < bestLevel {
+				best, bestLevel = f+smooth/2, level
+			}
+		}
+
+		end := best * frameBytes
+		chunks = append(chunks, Chunk{Start: bytesToDuration(start, bytesPerSec), PCM: pcm[start:end]})
+		startFrame = best
+	}
+}
+
+func bytesToDuration(n, bytesPerSec int) time.Duration {
+	return time.Duration(n) * time.Second / time.Duration(bytesPerSec)
+}
+
+// SplitUtterances returns the spoken parts of 16-bit mono PCM, separated
+// wherever the speaker pauses for at least minPause. Leading, trailing and
+// in-between silence is dropped. Utterances longer than maxLen are cut at
+// their quietest point, so each chunk suits a single caption.
+func SplitUtterances(pcm []byte, sampleRate int, minPause, maxLen time.Duration) []Chunk {
+	speech := speechFrames(pcm, sampleRate, 0)
+	if len(speech) == 0 {
+		return nil
+	}
+	frameBytes := sampleRate * int(frameDuration/time.Millisecond) / 1000 * 2
+	bytesPerSec := sampleRate * 2
+	pauseFrames := int(minPause / frameDuration)
+	const pad = 5 // frames of context kept around speech (100ms)
+
+	var chunks []Chunk
+	emit := func(from, to int) {
+		from = max(from-pad, 0)
+		to = min(to+pad, len(speech))
+		start, end := from*frameBytes, to*frameBytes
+		for _, c := range SplitAtSilence(pcm[start:end], sampleRate, maxLen) {
+			c.Start += bytesToDuration(start, bytesPerSec)
+			chunks = append(chunks, c)
+		}
+	}
+
+	runStart, lastSpeech := -1, -1
+	for f, s := range speech {
+		if !s {
+			continue
+		}
+		if runStart >= 0 && f-lastSpeech > pauseFrames {
+			emit(runStart, lastSpeech+1)
+			runStart = -1
+		}
+		if runStart < 0 {
+			runStart = f
+		}
+		lastSpeech = f
+	}
+	if runStart >= 0 {
+		emit(runStart, lastSpeech+1)
+	}
+	return chunks
+}
+
+// speechThreshold estimates the level separating speech from background:
+// a few times the noise floor (10th percentile frame), but never below
+// -46 dBFS so a clean digital silence doesn't make breaths count as speech.
+// It stays under half the peak, so a clip that is speech throughout (and
+// has no real noise floor) still counts as speech.
+func speechThreshold(rms []float64) float64 {
+	sorted := append([]float64(nil), rms...)
+	slices.Sort(sorted)
+	floor := sorted[len(sorted)/10]
+	peak := sorted[len(sorted)-1]
+	return min(max(floor*3, 0.005), peak/2)
+}
@@ -0,0 +1,91 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+)
+
+// tone returns d of a sine at freq Hz and peak amplitude amp (full scale 1)
+// as 16-bit mono PCM
+func tone(freq, amp float64, d time.Duration, rate int) []byte {
+	n := int(d.Seconds() * float64(rate))
+	pcm := make([]byte, 2*n)
+	for i := range n {
+		v := amp * math.Sin(2*math.Pi*freq*float64(i)/float64(rate))
+		binary.LittleEndian.PutUint16(pcm[2*i:], uint16(int16(math.Round(v*32767))))
+	}
+	return pcm
+}
+
+// silence returns d of digital silence as 16-bit mono PCM
+func silence(d time.Duration, rate int) []byte {
+	return make([]byte, 2*int(d.Seconds()*float64(rate)))
+}
+
+func concat(parts ...[]byte) []byte {
+	var out []byte
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	return out
+}
+
+func TestSplitAtSilenceShortLimit(t *testing.T) {
+	pcm := make([]byte, SampleRate*2/10) // 100ms
+	chunks := SplitAtSilence(pcm, SampleRate, 5*time.Millisecond)
+	if len(chunks) != 5 {
+		t.Fatalf("got %d chunks of 100ms at a 5ms limit, want one per 20ms frame", len(chunks))
+	}
+	var total int
+	for i, c := range chunks {
+		if want := time.Duration(i) * frameDuration; c.Start != want {
+			t.Errorf("chunk %d starts at %s, want %s", i, c.Start, want)
+		}
+		total += len(c.PCM)
+	}
+	if total != len(pcm) {
+		t.Errorf("chunks cover %d bytes, want %d", total, len(pcm))
+	}
+}
+
+func TestSplitUtterances(t *testing.T) {
+	const rate = 16000
+	pcm := concat(
+		silence(time.Second, rate),
+		tone(440, 0.5, time.Second, rate),
+		silence(time.Second, rate),
+		tone(440, 0.5, 3*time.Second, rate),
+		silence(time.Second, rate),
+	)
+	chunks := SplitUtterances(pcm, rate, 400*time.Millisecond, 2*time.Second)
+	if len(chunks) < 3 {
+		t.Fatalf("got %d utterances, want the first and the second cut in pieces", len(chunks))
+	}
+	end := func(c Chunk) time.Duration { return c.Start + bytesToDuration(len(c.PCM), rate*2) }
+	near := func(got, want time.Duration) bool { return got >= want-2*frameDuration && got <= want+2*frameDuration }
+
+	// Speech at 1-2s and 3-6s, padded by 100ms
+	if first := chunks[0]; !near(first.Start, 900*time.Millisecond) || !near(end(first), 2100*time.Millisecond) {
+		t.Errorf("first utterance spans %s-%s, want about 900ms-2.1s", first.Start, end(first))
+	}
+	second := chunks[1:]
+	if !near(second[0].Start, 2900*time.Millisecond) || !near(end(second[len(second)-1]), 6100*time.Millisecond) {
+		t.Errorf("second utterance spans %s-%s, want about 2.9s-6.1s", second[0].Start, end(second[len(second)-1]))
+	}
+	for i, c := range second {
+		if d := end(c) - c.Start; d > 2*time.Second {
+			t.Errorf("piece %d is %s long, over the 2s limit", i, d)
+		}
+		if i > 0 && c.Start != end(second[i-1]) {
+			t.Errorf("piece %d starts at %s, not where the previous one ends (%s)", i, c.Start, end(second[i-1]))
+		}
+	}
+}
+
+func TestSplitUtterancesSilence(t *testing.T) {
+	if chunks := SplitUtterances(silence(2*time.Second, 16000), 16000, 400*time.Millisecond, 8*time.Second); len(chunks) != 0 {
+		t.Errorf("got %d utterances in silence", len(chunks))
+	}
+}
@@ -0,0 +1,205 @@
+package audio
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ontypehq/vox/internal/audio/wav"
+)
+
+// Sink receives synthesized 16-bit mono PCM at SampleRate
+type Sink interface {
+	// Write queues audio. Safe to call from any goroutine.
+	Write(pcm []byte)
+	// Close marks the end of the audio and returns once it has all been
+	// played (or written)
+	Close()
+	// Stop abandons the audio at once
+	Stop()
+}
+
+// Player is a Sink that plays in real time, so it can be paused and its
+// progress followed. StreamPlayer is one.
+type Player interface {
+	Sink
+	Pause()
+	Resume()
+	Paused() bool
+	Position() time.Duration
+	Duration() time.Duration
+	OnProgress(fn func(position, duration time.Duration))
+}
+
+// Sink specs accepted by OpenSink
+const (
+	SinkDevice = "device" // the sound card, via oto or malgo
+	SinkNull   = "null"   // discard audio
+	sinkFile   = "file:"  // file:PATH writes WAV
+	sinkCmd    = "cmd:"   // cmd:COMMAND pipes WAV to the command's stdin
+)
+
+// OpenSink opens the sink a spec names: "device" (the output device, see
+// NewStreamPlayer), "null", "file:PATH" or "cmd:COMMAND"
+func OpenSink(spec, device string) (Sink, error) {
+	switch {
+	case spec == SinkDevice:
+		return NewStreamPlayer(device)
+	case spec == SinkNull:
+		return NullSink{}, nil
+	case strings.HasPrefix(spec, sinkFile):
+		return NewFileSink(strings.TrimPrefix(spec, sinkFile))
+	case strings.HasPrefix(spec, sinkCmd):
+		return NewCommandSink(strings.TrimPrefix(spec, sinkCmd))
+	}
+	return nil, fmt.Errorf("unknown sink %q (use device, null, file:PATH or cmd:COMMAND)", spec)
+}
+
+// NullSink discards audio, for machines without sound and for tests
+type NullSink struct{}
+
+func (NullSink) Write([]byte) {}
+func (NullSink) Close()       {}
+func (NullSink) Stop()        {}
+
+// FileSink writes audio to a WAV file. Sinks opened on the same path in one
+// run get numbered files (out.wav, out-2.wav, ...), so each message of a
+// listen session is kept.
+type FileSink struct {
+	mu   sync.Mutex
+	f    *os.File
+	n    int
+	done bool
+}
+
+var (
+	fileSinkMu   sync.Mutex
+	fileSinkUses = map[string]int{}
+)
+
+func NewFileSink(path string) (*FileSink, error) {
+	if path == "" {
+		return nil, fmt.Errorf("file sink needs a path (file:PATH)")
+	}
+	fileSinkMu.Lock()
+	fileSinkUses[path]++
+	if n := fileSinkUses[path]; n > 1 {
+		ext := filepath.Ext(path)
+		path = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(path, ext), n, ext)
+	}
+	fileSinkMu.Unlock()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("file sink: %w", err)
+	}
+	if _, err := f.Write(wav.StreamHeader(SampleRate)); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("file sink: %w", err)
+	}
+	return &FileSink{f: f}, nil
+}
+
+func (s *FileSink) Write(pcm []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return
+	}
+	n, _ := s.f.Write(pcm)
+	s.n += n
+}
+
+// Close fills in the WAV sizes and closes the file
+func (s *FileSink) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return
+	}
+	s.done = true
+	s.f.WriteAt(wav.Header(s.n, SampleRate, 1), 0)
+	s.f.Close()
+}
+
+// Stop keeps what was written so far
+func (s *FileSink) Stop() {
+	s.Close()
+}
+
+// CommandSink pipes audio as a WAV stream to an external player such as
+// aplay or paplay
+type CommandSink struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	mu    sync.Mutex
+	err   error // first write error; the player has gone
+	once  sync.Once
+}
+
+// NewCommandSink starts command (split on spaces, no shell) with the audio
+// on its stdin
+func NewCommandSink(command string) (*CommandSink, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("command sink needs a command (cmd:COMMAND)")
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("command sink: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("command sink: %w", err)
+	}
+	s := &CommandSink{cmd: cmd, stdin: stdin}
+	s.Write(wav.StreamHeader(SampleRate))
+	return s, nil
+}
+
+func (s *CommandSink) Write(pcm []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err == nil {
+		_, s.err = s.stdin.Write(pcm)
+	}
+}
+
+// Close ends the stream and waits for the player to finish
+func (s *CommandSink) Close() {
+	s.once.Do(func() {
+		s.stdin.Close()
+		s.cmd.Wait()
+	})
+}
+
+// Stop kills the player, also cutting short a Close that is waiting on it
+func (s *CommandSink) Stop() {
+	s.cmd.Process.Kill()
+	s.Close()
+}
+
+// fallbackPlayers are tried in order when there is no usable sound device
+// through oto, e.g. on a Linux box where only PulseAudio works
+var fallbackPlayers = []string{
+	"paplay",
+	"aplay -q",
+	"ffplay -nodisp -autoexit -loglevel quiet -i -",
+}
+
+// FallbackPlayer returns the command line of the first external player
+// found on PATH, or "" if there is none
+func FallbackPlayer() string {
+	for _, p := range fallbackPlayers {
+		if _, err := exec.LookPath(strings.Fields(p)[0]); err == nil {
+			return p
+		}
+	}
+	return ""
+}
@@ -0,0 +1,247 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+	"sync"
+	"time"
+)
+
+// VADConfig tunes voice activity detection. Zero values pick defaults.
+type VADConfig struct {
+	// Threshold is the speech level in dBFS (e.g. -40). Zero adapts to the
+	// background noise instead.
+	Threshold float64
+	// Hangover is the trailing silence that ends an utterance
+	Hangover time.Duration
+	// MaxLength stops capture after this much audio from the first speech;
+	// zero means no limit
+	MaxLength time.Duration
+	// MinSpeech is the shortest burst that counts as speech starting, so
+	// clicks and bumps don't trigger capture
+	MinSpeech time.Duration
+}
+
+const (
+	defaultHangover  = time.Second
+	defaultMinSpeech = 100 * time.Millisecond
+	// speechPad is kept around detected speech when trimming, so soft word
+	// onsets and endings survive
+	speechPad = 150 * time.Millisecond
+	// minSpeechLevel is the adaptive threshold's floor (-50 dBFS)
+	minSpeechLevel = 0.00316
+	// fricativeZCR is the zero-crossing rate (crossings per sample) above
+	// which a quiet frame is treated as an unvoiced consonant (s, f, sh)
+	// rather than silence
+	fricativeZCR = 0.25
+)
+
+func (c VADConfig) withDefaults() VADConfig {
+	if c.Hangover <= 0 {
+		c.Hangover = defaultHangover
+	}
+	if c.MinSpeech <= 0 {
+		c.MinSpeech = defaultMinSpeech
+	}
+	return c
+}
+
+// DBFS converts a level in dBFS to linear amplitude (0-1)
+func DBFS(db float64) float64 {
+	return math.Pow(10, db/20)
+}
+
+// frameStats returns the RMS level (0-1) and zero-crossing rate of a frame
+// of 16-bit mono PCM
+func frameStats(frame []byte) (rms, zcr float64) {
+	n := len(frame) / 2
+	if n == 0 {
+		return 0, 0
+	}
+	var sum float64
+	var crossings int
+	prev := int16(binary.LittleEndian.Uint16(frame))
+	for i := range n {
+		s := int16(binary.LittleEndian.Uint16(frame[i*2:]))
+		f := float64(s) / 32768
+		sum += f * f
+		if (s >= 0) != (prev >= 0) {
+			crossings++
+		}
+		prev = s
+	}
+	return math.Sqrt(sum / float64(n)), float64(crossings) / float64(n)
+}
+
+// isSpeech classifies a frame by energy, letting quieter frames through
+// when their zero-crossing rate says they are fricatives
+func isSpeech(rms, zcr, threshold float64) bool {
+	return rms > threshold || (rms > threshold/2 && zcr > fricativeZCR)
+}
+
+// VAD detects when a speaker starts and stops talking in a live stream of
+// 16-bit mono PCM. Feed it with Write (safe to call from the audio thread);
+// Done is closed once the speaker has finished.
+type VAD struct {
+	cfg        VADConfig
+	frameBytes int
+
+	mu        sync.Mutex
+	pending   []byte
+	frames    int     // frames processed
+	floor     float64 // running noise floor estimate
+	run       int     // consecutive speech frames
+	silence   int     // consecutive non-speech frames since speech started
+	startedAt int     // frame where speech started, -1 before
+	done      chan struct{}
+	closed    bool
+}
+
+// NewVAD returns a detector for sampleRate audio
+func NewVAD(sampleRate int, cfg VADConfig) *VAD {
+	return &VAD{
+		cfg:        cfg.withDefaults(),
+		frameBytes: sampleRate * int(frameDuration/time.Millisecond) / 1000 * 2,
+		startedAt:  -1,
+		done:       make(chan struct{}),
+	}
+}
+
+// Write analyzes the next block of audio
+func (v *VAD) Write(pcm []byte) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.closed || v.frameBytes == 0 {
+		return
+	}
+	v.pending = append(v.pending, pcm...)
+	for len(v.pending) >= v.frameBytes && !v.closed {
+		rms, zcr := frameStats(v.pending[:v.frameBytes])
+		v.pending = v.pending[v.frameBytes:]
+		v.frame(rms, zcr)
+	}
+}
+
+func (v *VAD) frame(rms, zcr float64) {
+	v.frames++
+	if v.frames == 1 {
+		v.floor = rms
+	}
+	threshold := v.threshold()
+	speech := isSpeech(rms, zcr, threshold)
+	if !speech {
+		// Track the background slowly so speech doesn't drag it up
+		v.floor = 0.95*v.floor + 0.05*rms
+	}
+
+	if v.startedAt < 0 {
+		if speech {
+			v.run++
+		} else {
+			v.run = 0
+		}
+		if v.run >= frames(v.cfg.MinSpeech) {
+			v.startedAt = v.frames - v.run
+		}
+		return
+	}
+
+	if speech {
+		v.silence = 0
+	} else {
+		v.silence++
+	}
+	if v.silence >= frames(v.cfg.Hangover) ||
+		(v.cfg.MaxLength > 0 && v.frames-v.startedAt >= frames(v.cfg.MaxLength)) {
+		v.closed = true
+		close(v.done)
+	}
+}
+
+func (v *VAD) threshold() float64 {
+	if v.cfg.Threshold != 0 {
+		return DBFS(v.cfg.Threshold)
+	}
+	return max(v.floor*4, minSpeechLevel) // ~12 dB above the background
+}
+
+// Started reports whether speech has been detected
+func (v *VAD) Started() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.startedAt >= 0
+}
+
+// Done is closed when the speaker has been silent for the hangover, or the
+// maximum length is reached
+func (v *VAD) Done() <-chan struct{} {
+	return v.done
+}
+
+func frames(d time.Duration) int {
+	return max(int(d/frameDuration), 1)
+}
+
+// TrimSilence drops leading and trailing silence from 16-bit mono PCM,
+// keeping a little padding around the speech. It returns nil if there is no
+// speech at all.
+func TrimSilence(pcm []byte, sampleRate int, cfg VADConfig) []byte {
+	speech := speechFrames(pcm, sampleRate, cfg.Threshold)
+	first, last := -1, -1
+	for f, s := range speech {
+		if s {
+			if first < 0 {
+				first = f
+			}
+			last = f
+		}
+	}
+	if first < 0 {
+		return nil
+	}
+	frameBytes := sampleRate * int(frameDuration/time.Millisecond) / 1000 * 2
+	pad := frames(speechPad)
+	start := max(first-pad, 0) * frameBytes
+	end := min((last+1+pad)*frameBytes, len(pcm))
+	return pcm[start:end]
+}
+
+// KeepSpeech trims leading and trailing silence and shortens pauses longer
+// than maxPause, so a sample contains only speech
+func KeepSpeech(pcm []byte, sampleRate int, maxPause time.Duration) []byte {
+	var out []byte
+	for _, c := range SplitUtterances(pcm, sampleRate, maxPause, 0) {
+		out = append(out, c.PCM...)
+	}
+	return out
+}
+
+// speechFrames classifies each 20ms frame of a complete recording. A zero
+// threshold is derived from the recording's own noise floor.
+func speechFrames(pcm []byte, sampleRate int, thresholdDB float64) []bool {
+	frameBytes := sampleRate * int(frameDuration/time.Millisecond) / 1000 * 2
+	if frameBytes == 0 {
+		return nil
+	}
+	n := len(pcm) / frameBytes
+	rms := make([]float64, n)
+	zcr := make([]float64, n)
+	for f := range n {
+		rms[f], zcr[f] = frameStats(pcm[f*frameBytes : (f+1)*frameBytes])
+	}
+	if n == 0 {
+		return nil
+	}
+
+	// The floor keeps a recording of nothing but room noise from passing
+	// as speech
+	threshold := max(speechThreshold(rms), minSpeechLevel)
+	if thresholdDB != 0 {
+		threshold = DBFS(thresholdDB)
+	}
+	out := make([]bool, n)
+	for f := range n {
+		out[f] = isSpeech(rms[f], zcr[f], threshold)
+	}
+	return out
+}
@@ -0,0 +1,102 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math/rand/v2"
+	"testing"
+	"time"
+)
+
+// noise returns d of uniform white noise peaking at amp as 16-bit mono PCM,
+// the same every time
+func noise(amp float64, d time.Duration, rate int) []byte {
+	r := rand.New(rand.NewPCG(1, 2))
+	n := int(d.Seconds() * float64(rate))
+	pcm := make([]byte, 2*n)
+	for i := range n {
+		binary.LittleEndian.PutUint16(pcm[2*i:], uint16(int16((r.Float64()*2-1)*amp*32767)))
+	}
+	return pcm
+}
+
+const vadRate = 16000
+
+// roomTone is background noise at about -60 dBFS
+func roomTone(d time.Duration) []byte {
+	return noise(0.001, d, vadRate)
+}
+
+func pcmDuration(pcm []byte) time.Duration {
+	return bytesToDuration(len(pcm), vadRate*2)
+}
+
+func TestTrimSilence(t *testing.T) {
+	pcm := concat(roomTone(time.Second), tone(300, 0.3, time.Second, vadRate), roomTone(time.Second))
+	got := TrimSilence(pcm, vadRate, VADConfig{})
+
+	// One second of speech plus 150ms of padding each side
+	if d := pcmDuration(got); d < 1300*time.Millisecond-2*frameDuration || d > 1300*time.Millisecond+2*frameDuration {
+		t.Errorf("trimmed to %s, want about 1.3s", d)
+	}
+	// got is a slice of pcm, so the difference in capacity is where it starts
+	if offset := pcmDuration(pcm[:cap(pcm)-cap(got)]); offset < 850*time.Millisecond-frameDuration || offset > 850*time.Millisecond+frameDuration {
+		t.Errorf("speech kept from %s, want about 850ms", offset)
+	}
+}
+
+func TestTrimSilenceNoSpeech(t *testing.T) {
+	if got := TrimSilence(roomTone(2*time.Second), vadRate, VADConfig{}); got != nil {
+		t.Errorf("kept %s of room tone", pcmDuration(got))
+	}
+}
+
+// feed writes pcm to v in 20ms blocks and returns how much had been written
+// when v finished, or -1 if it didn't
+func feed(v *VAD, pcm []byte) time.Duration {
+	const block = vadRate * 2 / 50
+	for i := 0; i < len(pcm); i += block {
+		v.Write(pcm[i:min(i+block, len(pcm))])
+		select {
+		case <-v.Done():
+			return pcmDuration(pcm[:min(i+block, len(pcm))])
+		default:
+		}
+	}
+	return -1
+}
+
+func TestVADStopsAfterHangover(t *testing.T) {
+	v := NewVAD(vadRate, VADConfig{Hangover: 500 * time.Millisecond})
+	if feed(v, roomTone(time.Second)) >= 0 || v.Started() {
+		t.Fatal("room tone started or ended the utterance")
+	}
+	if feed(v, tone(300, 0.3, time.Second, vadRate)) >= 0 {
+		t.Fatal("finished while speaking")
+	}
+	if !v.Started() {
+		t.Fatal("speech not detected")
+	}
+	stop := feed(v, roomTone(time.Second))
+	if stop < 500*time.Millisecond-frameDuration || stop > 500*time.Millisecond+2*frameDuration {
+		t.Errorf("stopped %s into the silence, want the 500ms hangover", stop)
+	}
+}
+
+func TestVADMaxLength(t *testing.T) {
+	v := NewVAD(vadRate, VADConfig{MaxLength: time.Second})
+	feed(v, roomTone(500*time.Millisecond))
+	stop := feed(v, tone(300, 0.3, 3*time.Second, vadRate))
+	if stop < time.Second-frameDuration || stop > time.Second+2*frameDuration {
+		t.Errorf("stopped %s into speech, want the 1s maximum", stop)
+	}
+}
+
+func TestVADIgnoresClicks(t *testing.T) {
+	v := NewVAD(vadRate, VADConfig{})
+	feed(v, roomTone(500*time.Millisecond))
+	feed(v, tone(300, 0.5, 40*time.Millisecond, vadRate))
+	feed(v, roomTone(500*time.Millisecond))
+	if v.Started() {
+		t.Error("a 40ms click counted as speech")
+	}
+}
@@ -0,0 +1,81 @@
+package wav
+
+import "math"
+
+const (
+	// zeroCrossings of the sinc kept on each side of the filter center;
+	// more is sharper and slower
+	zeroCrossings = 16
+	// kernelSteps is the table resolution per zero crossing
+	kernelSteps = 512
+	// kaiserBeta trades passband ripple for stopband attenuation (~80 dB)
+	kaiserBeta = 8.6
+)
+
+// Resample converts mono samples from one rate to another with a
+// Kaiser-windowed sinc filter. When downsampling, the filter cutoff drops to
+// the new Nyquist frequency so nothing above it aliases into speech.
+func Resample(x []float32, from, to int) []float32 {
+	if from == to || len(x) == 0 {
+		return x
+	}
+
+	// Cutoff as a fraction of the input Nyquist frequency, with a little
+	// headroom for the transition band
+	scale := min(1, float64(to)/float64(from)) * 0.95
+	halfWidth := zeroCrossings / scale // in input samples
+	kernel := kaiserSinc()
+
+	n := int(int64(len(x)) * int64(to) / int64(from))
+	out := make([]float32, n)
+	step := float64(from) / float64(to)
+	for j := range out {
+		center := float64(j) * step
+		lo := max(int(math.Ceil(center-halfWidth)), 0)
+		hi := min(int(center+halfWidth), len(x)-1)
+
+		var sum float64
+		for i := lo; i <= hi; i++ {
+			pos := math.Abs(center-float64(i)) * scale * kernelSteps
+			k := int(pos)
+			if k >= len(kernel)-1 {
+				continue
+			}
+			frac := pos - float64(k)
+			w := kernel[k] + (kernel[k+1]-kernel[k])*frac
+			sum += float64(x[i]) * w
+		}
+		out[j] = float32(sum * scale)
+	}
+	return out
+}
+
+// kaiserSinc tabulates one side of the windowed sinc, indexed by distance
+// from the center in zero crossings times kernelSteps
+func kaiserSinc() []float64 {
+	table := make([]float64, zeroCrossings*kernelSteps+2)
+	norm := besselI0(kaiserBeta)
+	for i := range table {
+		u := float64(i) / kernelSteps
+		if u > zeroCrossings {
+			break
+		}
+		sinc := 1.0
+		if u > 0 {
+			sinc = math.Sin(math.Pi*u) / (math.Pi * u)
+		}
+		r := u / zeroCrossings
+		table[i] = sinc * besselI0(kaiserBeta*math.Sqrt(1-r*r)) / norm
+	}
+	return table
+}
+
+// besselI0 is the zeroth-order modified Bessel function of the first kind
+func besselI0(x float64) float64 {
+	sum, term := 1.0, 1.0
+	for k := 1; term > 1e-12*sum; k++ {
+		term *= (x / (2 * float64(k))) * (x / (2 * float64(k)))
+		sum += term
+	}
+	return sum
+}
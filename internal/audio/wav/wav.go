@@ -0,0 +1,291 @@
+// Package wav reads and writes RIFF/WAVE files
+package wav
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+)
+
+// ErrNotWAV is returned when data doesn't start with a RIFF/WAVE header
+var ErrNotWAV = errors.New("not a WAV file")
+
+// Format tags from the fmt chunk
+const (
+	tagPCM        = 0x0001
+	tagFloat      = 0x0003
+	TagALaw       = 0x0006
+	TagULaw       = 0x0007
+	tagExtensible = 0xFFFE
+)
+
+// Format describes the sample layout of a WAV file
+type Format struct {
+	SampleRate    int
+	Channels      int
+	BitsPerSample int
+	Float         bool // IEEE float samples instead of integer PCM
+}
+
+// String describes the format for humans, e.g. "44100 Hz stereo 24-bit"
+func (f Format) String() string {
+	var ch string
+	switch f.Channels {
+	case 1:
+		ch = "mono"
+	case 2:
+		ch = "stereo"
+	default:
+		ch = fmt.Sprintf("%d-channel", f.Channels)
+	}
+	kind := fmt.Sprintf("%d-bit", f.BitsPerSample)
+	if f.Float {
+		kind += " float"
+	}
+	return fmt.Sprintf("%d Hz %s %s", f.SampleRate, ch, kind)
+}
+
+// Is reports whether the format is already 16-bit mono PCM at sampleRate
+func (f Format) Is(sampleRate int) bool {
+	return f.SampleRate == sampleRate && f.Channels == 1 && f.BitsPerSample == 16 && !f.Float
+}
+
+// Audio is a decoded WAV file
+type Audio struct {
+	Format Format
+	Data   []byte // raw interleaved sample data from the data chunk
+}
+
+// Decode parses a WAV file: integer PCM (8, 16, 24 or 32-bit), IEEE float
+// (32 or 64-bit), any channel count and sample rate, with plain or
+// WAVE_FORMAT_EXTENSIBLE headers
+func Decode(data []byte) (*Audio, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, ErrNotWAV
+	}
+
+	var a Audio
+	var haveFmt bool
+	pos := 12
+	for pos+8 <= len(data) {
+		id := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		body := data[pos+8:]
+		if size > len(body) || (id == "data" && size == 0) {
+			size = len(body) // tolerate truncated files and streaming headers
+		}
+		body = body[:size]
+
+		switch id {
+		case "fmt ":
+			f, err := parseFmt(body)
+			if err != nil {
+				return nil, err
+			}
+			a.Format = f
+			haveFmt = true
+		case "data":
+			if !haveFmt {
+				return nil, fmt.Errorf("wav: data chunk before fmt chunk")
+			}
+			frame := a.Format.Channels * a.Format.BitsPerSample / 8
+			a.Data = body[:len(body)/frame*frame]
+			return &a, nil
+		}
+		pos += 8 + size + size%2 // chunks are word-aligned
+	}
+	return nil, fmt.Errorf("wav: no data chunk")
+}
+
+func parseFmt(body []byte) (Format, error) {
+	if len(body) < 16 {
+		return Format{}, fmt.Errorf("wav: fmt chunk too short")
+	}
+	tag := binary.LittleEndian.Uint16(body[0:2])
+	f := Format{
+		Channels:      int(binary.LittleEndian.Uint16(body[2:4])),
+		SampleRate:    int(binary.LittleEndian.Uint32(body[4:8])),
+		BitsPerSample: int(binary.LittleEndian.Uint16(body[14:16])),
+	}
+
+	if tag == tagExtensible {
+		// cbSize, valid bits, channel mask, then a GUID whose first two
+		// bytes are the real format tag
+		if len(body) < 26 {
+			return Format{}, fmt.Errorf("wav: extensible fmt chunk too short")
+		}
+		tag = binary.LittleEndian.Uint16(body[24:26])
+	}
+
+	switch tag {
+	case tagPCM:
+		switch f.BitsPerSample {
+		case 8, 16, 24, 32:
+		default:
+			return Format{}, fmt.Errorf("wav: unsupported bit depth %d", f.BitsPerSample)
+		}
+	case tagFloat:
+		if f.BitsPerSample != 32 && f.BitsPerSample != 64 {
+			return Format{}, fmt.Errorf("wav: unsupported float bit depth %d", f.BitsPerSample)
+		}
+		f.Float = true
+	default:
+		return Format{}, fmt.Errorf("wav: unsupported format tag 0x%04x (compressed audio?)", tag)
+	}
+
+	if f.Channels < 1 {
+		return Format{}, fmt.Errorf("wav: invalid channel count %d", f.Channels)
+	}
+	if f.SampleRate < 1 {
+		return Format{}, fmt.Errorf("wav: invalid sample rate %d", f.SampleRate)
+	}
+	return f, nil
+}
+
+// Mono returns the audio as float samples in [-1, 1], averaging channels
+func (a *Audio) Mono() []float32 {
+	ch := a.Format.Channels
+	width := a.Format.BitsPerSample / 8
+	frames := len(a.Data) / (width * ch)
+	out := make([]float32, frames)
+	for i := range frames {
+		var sum float64
+		for c := range ch {
+			sum += a.sample(a.Data[(i*ch+c)*width:])
+		}
+		out[i] = float32(sum / float64(ch))
+	}
+	return out
+}
+
+// sample decodes the sample at the start of b as a float in [-1, 1]
+func (a *Audio) sample(b []byte) float64 {
+	if a.Format.Float {
+		if a.Format.BitsPerSample == 64 {
+			return math.Float64frombits(binary.LittleEndian.Uint64(b))
+		}
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+	}
+	switch a.Format.BitsPerSample {
+	case 8: // unsigned, centered on 128
+		return (float64(b[0]) - 128) / 128
+	case 16:
+		return float64(int16(binary.LittleEndian.Uint16(b))) / (1 << 15)
+	case 24:
+		v := int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24) >> 8
+		return float64(v) / (1 << 23)
+	default:
+		return float64(int32(binary.LittleEndian.Uint32(b))) / (1 << 31)
+	}
+}
+
+// Mono16 returns the audio as 16-bit mono PCM at its own sample rate
+func (a *Audio) Mono16() []byte {
+	if a.Format.Is(a.Format.SampleRate) {
+		return a.Data
+	}
+	return PCM16(a.Mono())
+}
+
+// Convert returns the audio as 16-bit mono PCM at sampleRate
+func (a *Audio) Convert(sampleRate int) []byte {
+	if a.Format.Is(sampleRate) {
+		return a.Data
+	}
+	return PCM16(Resample(a.Mono(), a.Format.SampleRate, sampleRate))
+}
+
+// PCM16 encodes float samples as 16-bit little-endian PCM, clipping
+// anything outside [-1, 1]
+func PCM16(samples []float32) []byte {
+	out := make([]byte, len(samples)*2)
+	for i, s := range samples {
+		v := math.Round(float64(s) * (1 << 15))
+		v = max(min(v, math.MaxInt16), math.MinInt16)
+		binary.LittleEndian.PutUint16(out[i*2:], uint16(int16(v)))
+	}
+	return out
+}
+
+// Header builds a 44-byte header for 16-bit PCM with dataLen bytes of samples
+func Header(dataLen, sampleRate, channels int) []byte {
+	h := make([]byte, 44)
+	copy(h[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(h[4:8], uint32(dataLen+36))
+	copy(h[8:12], "WAVE")
+	copy(h[12:16], "fmt ")
+	binary.LittleEndian.PutUint32(h[16:20], 16)
+	binary.LittleEndian.PutUint16(h[20:22], tagPCM)
+	binary.LittleEndian.PutUint16(h[22:24], uint16(channels))
+	binary.LittleEndian.PutUint32(h[24:28], uint32(sampleRate))
+	binary.LittleEndian.PutUint32(h[28:32], uint32(sampleRate*channels*2))
+	binary.LittleEndian.PutUint16(h[32:34], uint16(channels*2))
+	binary.LittleEndian.PutUint16(h[34:36], 16)
+	copy(h[36:40], "data")
+	binary.LittleEndian.PutUint32(h[40:44], uint32(dataLen))
+	return h
+}
+
+// StreamHeader builds a 16-bit mono header for a stream of unknown length.
+// The size fields hold the maximum value, which readers take to mean "until
+// end of file".
+func StreamHeader(sampleRate int) []byte {
+	h := Header(0, sampleRate, 1)
+	binary.LittleEndian.PutUint32(h[4:8], 0xFFFFFFFF)
+	binary.LittleEndian.PutUint32(h[40:44], 0xFFFFFFFF)
+	return h
+}
+
+// Encode wraps 16-bit mono PCM in a WAV container
+func Encode(pcm []byte, sampleRate int) []byte {
+	return append(Header(len(pcm), sampleRate, 1), pcm...)
+}
+
+// EncodeG711 wraps 8-bit μ-law (TagULaw) or A-law (TagALaw) mono samples
+// in a WAV container, as telephony systems expect
+func EncodeG711(data []byte, sampleRate int, tag uint16) []byte {
+	h := make([]byte, 58, 58+len(data)+1)
+	copy(h[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(h[4:8], uint32(50+len(data)+len(data)%2))
+	copy(h[8:12], "WAVE")
+	copy(h[12:16], "fmt ")
+	binary.LittleEndian.PutUint32(h[16:20], 18)
+	binary.LittleEndian.PutUint16(h[20:22], tag)
+	binary.LittleEndian.PutUint16(h[22:24], 1)
+	binary.LittleEndian.PutUint32(h[24:28], uint32(sampleRate))
+	binary.LittleEndian.PutUint32(h[28:32], uint32(sampleRate))
+	binary.LittleEndian.PutUint16(h[32:34], 1)
+	binary.LittleEndian.PutUint16(h[34:36], 8)
+	// h[36:38] is cbSize = 0; non-PCM formats also need a fact chunk
+	copy(h[38:42], "fact")
+	binary.LittleEndian.PutUint32(h[42:46], 4)
+	binary.LittleEndian.PutUint32(h[46:50], uint32(len(data)))
+	copy(h[50:54], "data")
+	binary.LittleEndian.PutUint32(h[54:58], uint32(len(data)))
+	out := append(h, data...)
+	if len(data)%2 == 1 {
+		out = append(out, 0) // chunks are word-aligned
+	}
+	return out
+}
+
+// WriteFile saves 16-bit mono PCM as a WAV file
+func WriteFile(path string, pcm []byte, sampleRate int) error {
+	return os.WriteFile(path, Encode(pcm, sampleRate), 0644)
+}
+
+// Normalize converts WAV data to a 16-bit mono WAV at sampleRate. It
+// returns the input unchanged (and its format) when it already matches,
+// and ErrNotWAV when data is some other container.
+func Normalize(data []byte, sampleRate int) ([]byte, Format, error) {
+	a, err := Decode(data)
+	if err != nil {
+		return nil, Format{}, err
+	}
+	if a.Format.Is(sampleRate) {
+		return data, a.Format, nil
+	}
+	return Encode(a.Convert(sampleRate), sampleRate), a.Format, nil
+}
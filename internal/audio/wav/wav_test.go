@@ -0,0 +1,175 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+)
+
+// sine returns n samples of a 440 Hz tone at rate as 16-bit PCM
+func sine(n, rate int) []byte {
+	pcm := make([]byte, n*2)
+	for i := range n {
+		v := 10000 * math.Sin(2*math.Pi*440*float64(i)/float64(rate))
+		binary.LittleEndian.PutUint16(pcm[i*2:], uint16(int16(v)))
+	}
+	return pcm
+}
+
+// file builds a WAV file with the given fmt chunk body and sample data
+func file(fmtBody, data []byte) []byte {
+	var b bytes.Buffer
+	b.WriteString("RIFF")
+	binary.Write(&b, binary.LittleEndian, uint32(4+8+len(fmtBody)+8+len(data)))
+	b.WriteString("WAVEfmt ")
+	binary.Write(&b, binary.LittleEndian, uint32(len(fmtBody)))
+	b.Write(fmtBody)
+	b.WriteString("data")
+	binary.Write(&b, binary.LittleEndian, uint32(len(data)))
+	b.Write(data)
+	return b.Bytes()
+}
+
+func fmtChunk(tag, channels, rate, bits int) []byte {
+	b := make([]byte, 16)
+	frame := channels * bits / 8
+	binary.LittleEndian.PutUint16(b[0:], uint16(tag))
+	binary.LittleEndian.PutUint16(b[2:], uint16(channels))
+	binary.LittleEndian.PutUint32(b[4:], uint32(rate))
+	binary.LittleEndian.PutUint32(b[8:], uint32(rate*frame))
+	binary.LittleEndian.PutUint16(b[12:], uint16(frame))
+	binary.LittleEndian.PutUint16(b[14:], uint16(bits))
+	return b
+}
+
+func TestEncodeDecode(t *testing.T) {
+	pcm := sine(1000, 24000)
+	a, err := Decode(Encode(pcm, 24000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Format{SampleRate: 24000, Channels: 1, BitsPerSample: 16}
+	if a.Format != want {
+		t.Errorf("format %v, want %v", a.Format, want)
+	}
+	if !bytes.Equal(a.Mono16(), pcm) {
+		t.Error("samples differ after a round trip")
+	}
+}
+
+func TestDecodeFormats(t *testing.T) {
+	// One stereo frame per format: left 0.5, right -0.25, mixed to 0.125
+	tests := []struct {
+		name   string
+		fmt    []byte
+		sample func(v float64) []byte
+	}{
+		{"8-bit", fmtChunk(tagPCM, 2, 8000, 8), func(v float64) []byte { return []byte{byte(128 + v*128)} }},
+		{"16-bit", fmtChunk(tagPCM, 2, 8000, 16), func(v float64) []byte {
+			return binary.LittleEndian.AppendUint16(nil, uint16(int16(v*(1<<15))))
+		}},
+		{"24-bit", fmtChunk(tagPCM, 2, 8000, 24), func(v float64) []byte {
+			u := uint32(int32(v * (1 << 23)))
+			return []byte{byte(u), byte(u >> 8), byte(u >> 16)}
+		}},
+		{"32-bit", fmtChunk(tagPCM, 2, 8000, 32), func(v float64) []byte {
+			return binary.LittleEndian.AppendUint32(nil, uint32(int32(v*(1<<31))))
+		}},
+		{"float32", fmtChunk(tagFloat, 2, 8000, 32), func(v float64) []byte {
+			return binary.LittleEndian.AppendUint32(nil, math.Float32bits(float32(v)))
+		}},
+		{"float64", fmtChunk(tagFloat, 2, 8000, 64), func(v float64) []byte {
+			return binary.LittleEndian.AppendUint64(nil, math.Float64bits(v))
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := append(tt.sample(0.5), tt.sample(-0.25)...)
+			a, err := Decode(file(tt.fmt, data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			mono := a.Mono()
+			if len(mono) != 1 || math.Abs(float64(mono[0])-0.125) > 0.01 {
+				t.Errorf("mono %v, want [0.125]", mono)
+			}
+		})
+	}
+}
+
+func TestDecodeRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"not riff", []byte("OggS\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00")},
+		{"no channels", file(fmtChunk(tagPCM, 0, 8000, 16), make([]byte, 4))},
+		{"no sample rate", file(fmtChunk(tagPCM, 1, 0, 16), make([]byte, 4))},
+		{"12-bit", file(fmtChunk(tagPCM, 1, 8000, 12), make([]byte, 4))},
+		{"compressed", file(fmtChunk(0x0055, 1, 8000, 16), make([]byte, 4))},
+		{"short fmt", file(make([]byte, 8), make([]byte, 4))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode(tt.data); err == nil {
+				t.Error("decoded without error")
+			}
+		})
+	}
+	if _, err := Decode([]byte("hello world, not audio")); !errors.Is(err, ErrNotWAV) {
+		t.Errorf("error %v, want ErrNotWAV", err)
+	}
+}
+
+func TestConvertLength(t *testing.T) {
+	tests := []struct{ from, to int }{
+		{48000, 16000},
+		{44100, 16000},
+		{8000, 24000},
+		{22050, 24000},
+		{16000, 16000},
+	}
+	for _, tt := range tests {
+		a, err := Decode(Encode(sine(tt.from, tt.from), tt.from)) // 1s
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := len(a.Convert(tt.to)) / 2
+		if got != tt.to {
+			t.Errorf("%d → %d Hz: %d samples for 1s, want %d", tt.from, tt.to, got, tt.to)
+		}
+	}
+}
+
+func TestResampleKeepsLevel(t *testing.T) {
+	a, _ := Decode(Encode(sine(48000, 48000), 48000))
+	out := Resample(a.Mono(), 48000, 16000)
+	var peak float64
+	for _, v := range out[100 : len(out)-100] { // skip the filter's edges
+		peak = max(peak, math.Abs(float64(v)))
+	}
+	if want := 10000.0 / 32768; math.Abs(peak-want) > want*0.02 {
+		t.Errorf("440 Hz peak %.4f after resampling, want %.4f", peak, want)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	in := Encode(sine(100, 16000), 16000)
+	out, f, err := Normalize(in, 16000)
+	if err != nil || !bytes.Equal(out, in) || f.SampleRate != 16000 {
+		t.Errorf("16 kHz mono input was changed (err %v)", err)
+	}
+
+	stereo := file(fmtChunk(tagPCM, 2, 48000, 16), make([]byte, 4*4800))
+	out, f, err = Normalize(stereo, 16000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := Decode(out)
+	if f.Channels != 2 || !a.Format.Is(16000) || len(a.Data) != 2*1600 {
+		t.Errorf("source %v, result %v with %d bytes", f, a.Format, len(a.Data))
+	}
+}
@@ -0,0 +1,261 @@
+// Package cache keeps an index of what is in ~/.vox/cache, so cached speech
+// and transcripts can be listed, searched and replayed
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// IndexFile is the manifest's name inside the cache directory
+const IndexFile = "index.json"
+
+// IDLength is how much of the cache hash is shown as an entry's ID
+const IDLength = 8
+
+// Kinds of cache entry
+const (
+	KindSpeech     = "tts"
+	KindTranscript = "asr"
+)
+
+// Entry describes one cached file
+type Entry struct {
+	Hash     string    `json:"hash"` // cache key hash the file is named after
+	File     string    `json:"file"` // name in the cache directory
+	Kind     string    `json:"kind"` // KindSpeech or KindTranscript
+	Text     string    `json:"text"` // spoken text, or the transcript
+	Voice    string    `json:"voice,omitempty"`
+	Model    string    `json:"model,omitempty"`
+	Lang     string    `json:"lang,omitempty"`
+	Instruct string    `json:"instruct,omitempty"`
+	Speed    float64   `json:"speed,omitempty"`
+	Volume   int       `json:"volume,omitempty"`
+	Pitch    float64   `json:"pitch,omitempty"`
+	Loudness float64   `json:"loudness,omitempty"` // LUFS target, 0 = not normalized
+	Source   string    `json:"source,omitempty"`   // transcribed file
+	Duration float64   `json:"duration,omitempty"` // seconds of audio
+	Size     int64     `json:"size"`
+	Created  time.Time `json:"created"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// ID is the short form of the hash used on the command line
+func (e Entry) ID() string {
+	return e.Hash[:min(IDLength, len(e.Hash))]
+}
+
+// Index is the manifest of a cache directory
+type Index struct {
+	dir     string
+	Entries []Entry
+}
+
+// Index updates wait this long for another process to finish its own, and
+// take over a lock left this old by a process that died holding it
+const (
+	lockWait  = 2 * time.Second
+	staleLock = 10 * time.Second
+)
+
+// Load reads the index of dir. A missing index is empty; the cache files
+// themselves stay usable without it. An index that can't be parsed is an
+// error, so it isn't overwritten.
+func Load(dir string) (*Index, error) {
+	ix := &Index{dir: dir}
+	data, err := os.ReadFile(filepath.Join(dir, IndexFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return ix, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &ix.Entries); err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Join(dir, IndexFile), err)
+	}
+	return ix, nil
+}
+
+// save writes the index through a temporary file, so a concurrent reader
+// never sees it half written
+func (ix *Index) save() error {
+	data, err := json.MarshalIndent(ix.Entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(ix.dir, IndexFile+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(ix.dir, IndexFile))
+}
+
+// update loads the index, lets fn change it and saves it, holding the lock
+// throughout so concurrent vox processes don't drop each other's changes.
+// fn returns false to leave the index as it was.
+func update(dir string, fn func(ix *Index) bool) error {
+	unlock, err := lock(dir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	ix, err := Load(dir)
+	if err != nil {
+		return err
+	}
+	if !fn(ix) {
+		return nil
+	}
+	return ix.save()
+}
+
+// lock takes the index lock file, waiting up to lockWait for its holder
+func lock(dir string) (unlock func(), err error) {
+	path := filepath.Join(dir, IndexFile+".lock")
+	deadline := time.Now().Add(lockWait)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleLock {
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("cache index is locked (remove %s if no vox is running)", path)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// Add records a file just written to the cache, replacing any entry for
+// the same file. Size, Created and LastUsed are filled in.
+func Add(dir string, e Entry) error {
+	if info, err := os.Stat(filepath.Join(dir, e.File)); err == nil {
+		e.Size = info.Size()
+	}
+	e.Created = time.Now().UTC().Truncate(time.Second)
+	e.LastUsed = e.Created
+
+	return update(dir, func(ix *Index) bool {
+		ix.Entries = slices.DeleteFunc(ix.Entries, func(old Entry) bool { return old.File == e.File })
+		ix.Entries = append(ix.Entries, e)
+		return true
+	})
+}
+
+// Touch marks the entry for file as used now. Files without an entry are
+// left alone.
+func Touch(dir, file string) error {
+	return update(dir, func(ix *Index) bool {
+		i := slices.IndexFunc(ix.Entries, func(e Entry) bool { return e.File == file })
+		if i < 0 {
+			return false
+		}
+		ix.Entries[i].LastUsed = time.Now().UTC().Truncate(time.Second)
+		return true
+	})
+}
+
+// Prune drops entries whose file is gone and reports how many cache files
+// have no entry (written before the index existed)
+func (ix *Index) Prune() (unindexed int, err error) {
+	gone := func(e Entry) bool {
+		_, err := os.Stat(filepath.Join(ix.dir, e.File))
+		return errors.Is(err, fs.ErrNotExist)
+	}
+	if slices.ContainsFunc(ix.Entries, gone) {
+		// Prune the index as it is now, keeping entries added since it was
+		// loaded
+		err = update(ix.dir, func(cur *Index) bool {
+			cur.Entries = slices.DeleteFunc(cur.Entries, gone)
+			ix.Entries = cur.Entries
+			return true
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	files, err := os.ReadDir(ix.dir)
+	if err != nil {
+		return 0, err
+	}
+	indexed := map[string]bool{}
+	for _, e := range ix.Entries {
+		indexed[e.File] = true
+	}
+	for _, f := range files {
+		if !f.IsDir() && !strings.HasPrefix(f.Name(), IndexFile) && !indexed[f.Name()] {
+			unindexed++
+		}
+	}
+	return unindexed, nil
+}
+
+// Recent returns the entries, most recently used first
+func (ix *Index) Recent() []Entry {
+	entries := slices.Clone(ix.Entries)
+	slices.SortStableFunc(entries, func(a, b Entry) int { return b.LastUsed.Compare(a.LastUsed) })
+	return entries
+}
+
+// Search returns the entries whose text, voice or source contains query,
+// ignoring case, most recently used first
+func (ix *Index) Search(query string) []Entry {
+	query = strings.ToLower(query)
+	var found []Entry
+	for _, e := range ix.Recent() {
+		for _, field := range []string{e.Text, e.Voice, e.Source} {
+			if strings.Contains(strings.ToLower(field), query) {
+				found = append(found, e)
+				break
+			}
+		}
+	}
+	return found
+}
+
+// Find looks an entry up by its ID or any unambiguous prefix of its hash.
+// A transcript cached as both text and JSON shares one hash; the most
+// recently used of the two is returned.
+func (ix *Index) Find(id string) (Entry, error) {
+	id = strings.ToLower(id)
+	var found []Entry
+	for _, e := range ix.Recent() {
+		if id != "" && strings.HasPrefix(e.Hash, id) {
+			found = append(found, e)
+		}
+	}
+	if len(found) == 0 {
+		return Entry{}, fmt.Errorf("no cache entry %q (see: vox cache list)", id)
+	}
+	for _, e := range found[1:] {
+		if e.Hash != found[0].Hash {
+			return Entry{}, fmt.Errorf("cache entry %q is ambiguous, give more of the ID", id)
+		}
+	}
+	return found[0], nil
+}
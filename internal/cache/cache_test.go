@@ -0,0 +1,204 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// writeIndex saves entries as dir's index
+func writeIndex(t *testing.T, dir string, entries ...Entry) {
+	t.Helper()
+	ix := &Index{dir: dir, Entries: entries}
+	if err := ix.save(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func load(t *testing.T, dir string) *Index {
+	t.Helper()
+	ix, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ix
+}
+
+func files(entries []Entry) []string {
+	var out []string
+	for _, e := range entries {
+		out = append(out, e.File)
+	}
+	return out
+}
+
+func TestConcurrentAdd(t *testing.T) {
+	dir := t.TempDir()
+	const n = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- Add(dir, Entry{Hash: fmt.Sprintf("%064x", i), File: fmt.Sprintf("%d.ogg", i), Kind: KindSpeech})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := len(load(t, dir).Entries); got != n {
+		t.Errorf("index has %d entries after %d concurrent adds", got, n)
+	}
+	if _, err := os.Stat(filepath.Join(dir, IndexFile+".lock")); !os.IsNotExist(err) {
+		t.Errorf("lock file left behind: %v", err)
+	}
+}
+
+func TestAddReplacesSameFile(t *testing.T) {
+	dir := t.TempDir()
+	Add(dir, Entry{Hash: "aa", File: "aa.txt", Text: "old"})
+	Add(dir, Entry{Hash: "aa", File: "aa.txt", Text: "new"})
+	ix := load(t, dir)
+	if len(ix.Entries) != 1 || ix.Entries[0].Text != "new" {
+		t.Errorf("entries = %+v, want only the new one", ix.Entries)
+	}
+}
+
+func TestCorruptIndexKept(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, IndexFile)
+	corrupt := []byte(`[{"hash": "aa", "file": `)
+	if err := os.WriteFile(path, corrupt, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(dir); err == nil {
+		t.Error("Load accepted a corrupt index")
+	}
+	if err := Add(dir, Entry{Hash: "bb", File: "bb.ogg"}); err == nil {
+		t.Error("Add succeeded over a corrupt index")
+	}
+	if err := Touch(dir, "aa.ogg"); err == nil {
+		t.Error("Touch succeeded over a corrupt index")
+	}
+	if data, _ := os.ReadFile(path); string(data) != string(corrupt) {
+		t.Errorf("corrupt index was overwritten with %q", data)
+	}
+}
+
+func TestMissingIndexIsEmpty(t *testing.T) {
+	if ix := load(t, t.TempDir()); len(ix.Entries) != 0 {
+		t.Errorf("got %d entries without an index", len(ix.Entries))
+	}
+}
+
+func TestStaleLockTakenOver(t *testing.T) {
+	dir := t.TempDir()
+	lockPath := filepath.Join(dir, IndexFile+".lock")
+	if err := os.WriteFile(lockPath, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * staleLock)
+	if err := os.Chtimes(lockPath, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := Add(dir, Entry{Hash: "aa", File: "aa.ogg"}); err != nil {
+		t.Fatalf("stale lock not taken over: %v", err)
+	}
+}
+
+func TestPruneAndRecent(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.ogg", "c.ogg", "old.pcm"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	writeIndex(t, dir,
+		Entry{Hash: "a", File: "a.ogg", LastUsed: now.Add(-3 * time.Hour)},
+		Entry{Hash: "b", File: "b.ogg", LastUsed: now}, // file deleted
+		Entry{Hash: "c", File: "c.ogg", LastUsed: now.Add(-time.Hour)},
+	)
+
+	ix := load(t, dir)
+	unindexed, err := ix.Prune()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unindexed != 1 {
+		t.Errorf("unindexed = %d, want 1 (old.pcm)", unindexed)
+	}
+	if got := files(ix.Recent()); !slices.Equal(got, []string{"c.ogg", "a.ogg"}) {
+		t.Errorf("Recent after Prune = %v, want [c.ogg a.ogg]", got)
+	}
+	if got := files(load(t, dir).Entries); !slices.Equal(got, []string{"a.ogg", "c.ogg"}) {
+		t.Errorf("saved index = %v, want the pruned entries", got)
+	}
+
+	// Using a.ogg moves it to the front
+	if err := Touch(dir, "a.ogg"); err != nil {
+		t.Fatal(err)
+	}
+	if got := files(load(t, dir).Recent()); !slices.Equal(got, []string{"a.ogg", "c.ogg"}) {
+		t.Errorf("Recent after Touch = %v, want [a.ogg c.ogg]", got)
+	}
+}
+
+func TestFind(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().UTC()
+	writeIndex(t, dir,
+		Entry{Hash: "abc123", File: "abc123.txt", LastUsed: now.Add(-time.Hour)},
+		Entry{Hash: "abc123", File: "abc123.json", LastUsed: now},
+		Entry{Hash: "abd456", File: "abd456.ogg", LastUsed: now.Add(-2 * time.Hour)},
+	)
+	ix := load(t, dir)
+
+	// The transcript cached as text and JSON shares a hash; the JSON one
+	// was used last
+	e, err := ix.Find("ABC1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.File != "abc123.json" {
+		t.Errorf("Find returned %s, want the most recently used abc123.json", e.File)
+	}
+	if e, err := ix.Find("abd"); err != nil || e.File != "abd456.ogg" {
+		t.Errorf("Find(abd) = %s, %v", e.File, err)
+	}
+	for _, id := range []string{"ab", "zzz", ""} {
+		if _, err := ix.Find(id); err == nil {
+			t.Errorf("Find(%q) succeeded", id)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().UTC()
+	writeIndex(t, dir,
+		Entry{Hash: "a", File: "a.ogg", Text: "Good morning", Voice: "Cherry", LastUsed: now.Add(-time.Hour)},
+		Entry{Hash: "b", File: "b.txt", Text: "meeting notes", Source: "/tmp/Morning.wav", LastUsed: now},
+		Entry{Hash: "c", File: "c.ogg", Text: "Good night", Voice: "Ethan", LastUsed: now},
+	)
+	ix := load(t, dir)
+	if got := files(ix.Search("MORNING")); !slices.Equal(got, []string{"b.txt", "a.ogg"}) {
+		t.Errorf("Search(MORNING) = %v, want [b.txt a.ogg]", got)
+	}
+	if got := files(ix.Search("cherry")); !slices.Equal(got, []string{"a.ogg"}) {
+		t.Errorf("Search(cherry) = %v", got)
+	}
+	if got := ix.Search("nothing"); len(got) != 0 {
+		t.Errorf("Search(nothing) = %v", files(got))
+	}
+}
@@ -10,7 +10,20 @@ import (
 const appDir = ".vox"
 
 type DashScopeConfig struct {
-	APIKey string `json:"api_key,omitempty"`
+	APIKey  string      `json:"api_key,omitempty"`
+	Region  string      `json:"region,omitempty"`   // "cn" (default) or "intl"
+	BaseURL string      `json:"base_url,omitempty"` // host root, overrides region (e.g. http://localhost:8080)
+	HTTPURL string      `json:"http_url,omitempty"` // full HTTP API root, overrides base_url
+	WSURL   string      `json:"ws_url,omitempty"`   // full realtime WebSocket URL, overrides base_url
+	Retry   RetryConfig `json:"retry,omitzero"`
+}
+
+// RetryConfig tunes retries of transient API failures. Zero values keep
+// the defaults (3 attempts, 500ms base delay, 8s cap).
+type RetryConfig struct {
+	MaxAttempts int `json:"max_attempts,omitempty"` // total tries, 1 disables retries
+	BaseDelayMS int `json:"base_delay_ms,omitempty"`
+	MaxDelayMS  int `json:"max_delay_ms,omitempty"`
 }
 
 type SlackConfig struct {
@@ -24,14 +37,53 @@ type Services struct {
 }
 
 type ListenConfig struct {
-	Channels []string          `json:"channels,omitempty"`  // default channel names or IDs
-	Ignore   []string          `json:"ignore,omitempty"`    // Slack user IDs or display names to skip
-	VoiceMap map[string]string `json:"voice_map,omitempty"` // slack user ID or display name → voice
+	Channels []string                `json:"channels,omitempty"`  // default channel names or IDs
+	Ignore   []string                `json:"ignore,omitempty"`    // Slack user IDs or display names to skip
+	VoiceMap map[string]VoiceMapping `json:"voice_map,omitempty"` // slack user ID or display name → voice
+}
+
+// VoiceMapping is the voice a listen user is read out in. In config.json it
+// is either the voice ID alone or an object that also sets volume and pitch:
+// {"voice": "Ethan", "volume": 70, "pitch": 0.9}.
+type VoiceMapping struct {
+	Voice  string  `json:"voice"`
+	Volume int     `json:"volume,omitempty"` // 1-100, 0 = engine default
+	Pitch  float64 `json:"pitch,omitempty"`  // 0.5-2.0, 0 = engine default
+}
+
+func (m *VoiceMapping) UnmarshalJSON(data []byte) error {
+	var voice string
+	if err := json.Unmarshal(data, &voice); err == nil {
+		*m = VoiceMapping{Voice: voice}
+		return nil
+	}
+	type plain VoiceMapping
+	return json.Unmarshal(data, (*plain)(m))
+}
+
+// MarshalJSON keeps the short string form for mappings that only pick a voice
+func (m VoiceMapping) MarshalJSON() ([]byte, error) {
+	if m.Volume == 0 && m.Pitch == 0 {
+		return json.Marshal(m.Voice)
+	}
+	type plain VoiceMapping
+	return json.Marshal(plain(m))
+}
+
+// AudioConfig picks the devices vox records from and plays to. Values are
+// device names (see vox devices); empty means the system default.
+type AudioConfig struct {
+	InputDevice  string  `json:"input_device,omitempty"`
+	OutputDevice string  `json:"output_device,omitempty"`
+	Sink         string  `json:"sink,omitempty"`     // device, null, file:PATH, cmd:COMMAND; empty = auto
+	Loudness     float64 `json:"loudness,omitempty"` // normalize speech to this LUFS (e.g. -16); 0 = off
 }
 
 type Config struct {
+	Provider string       `json:"provider,omitempty"` // speech engine, default "dashscope"
 	Services Services     `json:"services"`
 	Listen   ListenConfig `json:"listen,omitempty"`
+	Audio    AudioConfig  `json:"audio,omitzero"`
 }
 
 type State struct {
@@ -39,10 +91,24 @@ type State struct {
 	LastLang  string `json:"last_lang,omitempty"`
 }
 
+// Overrides holds per-invocation settings from global flags and their
+// environment variables. They take precedence over config.json and are
+// never saved.
+type Overrides struct {
+	Provider     string
+	Region       string
+	BaseURL      string
+	InputDevice  string
+	OutputDevice string
+	Sink         string
+	Loudness     float64
+}
+
 type AppConfig struct {
-	Config Config
-	State  State
-	Dir    string
+	Config    Config
+	State     State
+	Dir       string
+	Overrides Overrides
 }
 
 func Dir() string {
@@ -69,6 +135,8 @@ func Load() (*AppConfig, error) {
 			}
 			if err := json.Unmarshal(data, &legacy); err == nil && legacy.APIKey != "" {
 				ac.Config.Services.DashScope.APIKey = legacy.APIKey
+				// The legacy "provider" field named a model vendor, not an engine
+				ac.Config.Provider = ""
 				// Save migrated config
 				ac.SaveConfig()
 			}
@@ -89,6 +157,60 @@ func (ac *AppConfig) SaveState() error {
 	return writeJSON(filepath.Join(ac.Dir, "state.json"), ac.State)
 }
 
+// ProviderName returns the selected speech engine: flag > config
+func (ac *AppConfig) ProviderName() string {
+	if ac.Overrides.Provider != "" {
+		return ac.Overrides.Provider
+	}
+	return ac.Config.Provider
+}
+
+// InputDevice returns the microphone to record from: flag > config
+func (ac *AppConfig) InputDevice() string {
+	if ac.Overrides.InputDevice != "" {
+		return ac.Overrides.InputDevice
+	}
+	return ac.Config.Audio.InputDevice
+}
+
+// OutputDevice returns the device to play audio on: flag > config
+func (ac *AppConfig) OutputDevice() string {
+	if ac.Overrides.OutputDevice != "" {
+		return ac.Overrides.OutputDevice
+	}
+	return ac.Config.Audio.OutputDevice
+}
+
+// Sink returns where audio is played: flag > config, empty for auto
+func (ac *AppConfig) Sink() string {
+	if ac.Overrides.Sink != "" {
+		return ac.Overrides.Sink
+	}
+	return ac.Config.Audio.Sink
+}
+
+// Loudness returns the LUFS target speech is normalized to: flag > config,
+// 0 for off
+func (ac *AppConfig) Loudness() float64 {
+	if ac.Overrides.Loudness != 0 {
+		return ac.Overrides.Loudness
+	}
+	return ac.Config.Audio.Loudness
+}
+
+// DashScope returns the DashScope settings in effect: flag/env > config
+func (ac *AppConfig) DashScope() DashScopeConfig {
+	ds := ac.Config.Services.DashScope
+	// A flag replaces the whole endpoint selection from config
+	if ac.Overrides.Region != "" || ac.Overrides.BaseURL != "" {
+		ds.Region = ac.Overrides.Region
+		ds.BaseURL = ac.Overrides.BaseURL
+		ds.HTTPURL = ""
+		ds.WSURL = ""
+	}
+	return ds
+}
+
 func (ac *AppConfig) RequireAPIKey() (string, error) {
 	key := ac.Config.Services.DashScope.APIKey
 	if key == "" {
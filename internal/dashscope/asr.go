@@ -3,6 +3,7 @@ package dashscope
 import (
 	"encoding/base64"
 	"fmt"
+	"time"
 )
 
 const (
@@ -12,7 +13,16 @@ const (
 
 // ASRResult holds the transcription output
 type ASRResult struct {
-	Text string
+	Text     string
+	Segments []ASRSegment // per turn, realtime sessions only
+}
+
+// ASRSegment is the text of one turn and where it was spoken, relative to
+// the start of the audio
+type ASRSegment struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
 }
 
 // Transcribe sends audio to Qwen3-ASR via the multimodal generation endpoint.
@@ -50,8 +60,8 @@ func (c *Client) Transcribe(wavData []byte, context string) (*ASRResult, error)
 		},
 	}
 
-	resp, err := c.post(multimodalGenPath, body)
-	if err != nil {
+	var resp map[string]any
+	if err := c.post(multimodalGenPath, body, &resp); err != nil {
 		return nil, err
 	}
 
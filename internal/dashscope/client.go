@@ -2,140 +2,64 @@ package dashscope
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"fmt"
 	"io"
 	"net/http"
 )
 
-const (
-	httpEndpoint   = "https://dashscope.aliyuncs.com/api/v1"
-	enrollmentPath = "/services/audio/tts/customization"
-)
-
 // Client handles HTTP API calls to DashScope
 type Client struct {
 	apiKey     string
+	endpoint   string
 	httpClient *http.Client
+	Retry      RetryPolicy
 }
 
-func NewClient(apiKey string) *Client {
+func NewClient(apiKey string, ep Endpoints) *Client {
 	return &Client{
 		apiKey:     apiKey,
+		endpoint:   ep.HTTP,
 		httpClient: &http.Client{},
+		Retry:      DefaultRetry,
 	}
 }
 
-// EnrollVoice creates a cloned voice from audio data
-func (c *Client) EnrollVoice(name string, audioBase64 string) (string, error) {
-	body := map[string]any{
-		"model": ModelEnrollment,
-		"input": map[string]any{
-			"action":       "create",
-			"target_model": ModelVCRealtime,
-			"preferred_name": name,
-			"audio": map[string]string{
-				"data": "data:audio/wav;base64," + audioBase64,
-			},
-		},
-	}
-
-	resp, err := c.post(enrollmentPath, body)
-	if err != nil {
-		return "", err
-	}
-
-	output, ok := resp["output"].(map[string]any)
-	if !ok {
-		return "", fmt.Errorf("unexpected response: %v", resp)
-	}
-	voiceID, ok := output["voice"].(string)
-	if !ok {
-		return "", fmt.Errorf("no voice in response: %v", output)
-	}
-	return voiceID, nil
-}
-
-// ListVoices returns all enrolled custom voices
-func (c *Client) ListVoices(page, pageSize int) ([]map[string]any, error) {
-	body := map[string]any{
-		"model": ModelEnrollment,
-		"input": map[string]any{
-			"action":     "list",
-			"page_size":  pageSize,
-			"page_index": page,
-		},
-	}
-
-	resp, err := c.post(enrollmentPath, body)
-	if err != nil {
-		return nil, err
-	}
-
-	output, ok := resp["output"].(map[string]any)
-	if !ok {
-		return nil, nil
-	}
-
-	voicesRaw, ok := output["voice_list"].([]any)
-	if !ok {
-		return nil, nil
-	}
-
-	var voices []map[string]any
-	for _, v := range voicesRaw {
-		if m, ok := v.(map[string]any); ok {
-			voices = append(voices, m)
-		}
-	}
-	return voices, nil
-}
-
-// DeleteVoice removes an enrolled voice
-func (c *Client) DeleteVoice(voiceID string) error {
-	body := map[string]any{
-		"model": ModelEnrollment,
-		"input": map[string]any{
-			"action": "delete",
-			"voice":  voiceID,
-		},
-	}
-
-	_, err := c.post(enrollmentPath, body)
-	return err
+// post sends a JSON request and decodes the response into out, retrying
+// transient failures. Only use it for requests that are safe to repeat.
+func (c *Client) post(path string, body, out any) error {
+	return c.Retry.do(context.Background(), IsTemporary, func() error {
+		return c.postOnce(path, body, out)
+	})
 }
 
-func (c *Client) post(path string, body any) (map[string]any, error) {
+func (c *Client) postOnce(path string, body, out any) error {
 	jsonBody, err := json.Marshal(body)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	req, err := http.NewRequest("POST", httpEndpoint+path, bytes.NewReader(jsonBody))
+	req, err := http.NewRequest("POST", c.endpoint+path, bytes.NewReader(jsonBody))
 	if err != nil {
-		return nil, err
+		return err
 	}
 	req.Header.Set("Authorization", "Bearer "+c.apiKey)
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(respBody))
+		return parseHTTPError(resp.StatusCode, respBody)
 	}
 
-	var result map[string]any
-	if err := json.Unmarshal(respBody, &result); err != nil {
-		return nil, err
-	}
-	return result, nil
+	return json.Unmarshal(respBody, out)
 }
@@ -0,0 +1,69 @@
+package dashscope
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	httpAPIPath = "/api/v1"
+	wsAPIPath   = "/api-ws/v1/realtime"
+)
+
+// Endpoints are the HTTP and WebSocket API roots for one deployment
+type Endpoints struct {
+	Region string // "cn", "intl", or "custom"
+	HTTP   string // e.g. https://dashscope.aliyuncs.com/api/v1
+	WS     string // e.g. wss://dashscope.aliyuncs.com/api-ws/v1/realtime
+}
+
+// regionHosts maps region names to their API host. API keys are region-bound.
+var regionHosts = map[string]string{
+	"cn":   "https://dashscope.aliyuncs.com",      // Beijing
+	"intl": "https://dashscope-intl.aliyuncs.com", // Singapore
+}
+
+// DefaultEndpoints is the Beijing deployment
+var DefaultEndpoints = endpointsForBase("cn", regionHosts["cn"])
+
+// ResolveEndpoints picks the endpoints in effect. baseURL (a host root such as
+// http://localhost:8080) wins over region; httpURL and wsURL override the
+// individual API roots on top of that.
+func ResolveEndpoints(region, baseURL, httpURL, wsURL string) (Endpoints, error) {
+	ep := DefaultEndpoints
+	if region != "" {
+		host, ok := regionHosts[strings.ToLower(region)]
+		if !ok {
+			return Endpoints{}, fmt.Errorf("unknown dashscope region %q (use cn or intl)", region)
+		}
+		ep = endpointsForBase(strings.ToLower(region), host)
+	}
+	if baseURL != "" {
+		u, err := url.Parse(baseURL)
+		if err != nil || u.Host == "" {
+			return Endpoints{}, fmt.Errorf("invalid dashscope base URL %q", baseURL)
+		}
+		ep = endpointsForBase("custom", strings.TrimSuffix(baseURL, "/"))
+	}
+	if httpURL != "" {
+		ep.HTTP = strings.TrimSuffix(httpURL, "/")
+		ep.Region = "custom"
+	}
+	if wsURL != "" {
+		ep.WS = wsURL
+		ep.Region = "custom"
+	}
+	return ep, nil
+}
+
+func endpointsForBase(region, base string) Endpoints {
+	ws := base
+	switch {
+	case strings.HasPrefix(ws, "https://"):
+		ws = "wss://" + strings.TrimPrefix(ws, "https://")
+	case strings.HasPrefix(ws, "http://"):
+		ws = "ws://" + strings.TrimPrefix(ws, "http://")
+	}
+	return Endpoints{Region: region, HTTP: base + httpAPIPath, WS: ws + wsAPIPath}
+}
@@ -0,0 +1,203 @@
+package dashscope
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"time"
+)
+
+const enrollmentPath = "/services/audio/tts/customization"
+
+// Voice is an enrolled (cloned) voice
+type Voice struct {
+	ID            string    `json:"voice"`
+	PreferredName string    `json:"preferred_name,omitempty"`
+	TargetModel   string    `json:"target_model,omitempty"`
+	Language      string    `json:"language,omitempty"`
+	CreatedAt     time.Time `json:"gmt_create"`
+	UpdatedAt     time.Time `json:"gmt_modified"`
+}
+
+// UnmarshalJSON accepts the API's "2006-01-02 15:04:05" timestamps
+func (v *Voice) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		ID            string `json:"voice"`
+		PreferredName string `json:"preferred_name"`
+		TargetModel   string `json:"target_model"`
+		Language      string `json:"language"`
+		Created       string `json:"gmt_create"`
+		Modified      string `json:"gmt_modified"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*v = Voice{
+		ID:            raw.ID,
+		PreferredName: raw.PreferredName,
+		TargetModel:   raw.TargetModel,
+		Language:      raw.Language,
+		CreatedAt:     parseAPITime(raw.Created),
+		UpdatedAt:     parseAPITime(raw.Modified),
+	}
+	return nil
+}
+
+// VoicePage is one page of ListVoices
+type VoicePage struct {
+	Voices    []Voice
+	Page      int
+	PageSize  int
+	RequestID string
+}
+
+// EnrollResult describes a newly created voice
+type EnrollResult struct {
+	VoiceID     string
+	TargetModel string
+	RequestID   string
+}
+
+// DeleteResult confirms a deleted voice
+type DeleteResult struct {
+	VoiceID   string
+	RequestID string
+}
+
+// enrollmentResponse covers the create, list and delete actions
+type enrollmentResponse struct {
+	RequestID string `json:"request_id"`
+	Output    struct {
+		Voice       string  `json:"voice"`
+		TargetModel string  `json:"target_model"`
+		VoiceList   []Voice `json:"voice_list"`
+	} `json:"output"`
+}
+
+// EnrollVoice creates a cloned voice from audio data
+func (c *Client) EnrollVoice(name string, audioBase64 string) (*EnrollResult, error) {
+	body := map[string]any{
+		"model": ModelEnrollment,
+		"input": map[string]any{
+			"action":         "create",
+			"target_model":   ModelVCRealtime,
+			"preferred_name": name,
+			"audio": map[string]string{
+				"data": "data:audio/wav;base64," + audioBase64,
+			},
+		},
+	}
+
+	// Creating a voice isn't idempotent: a retried timeout could enroll it
+	// twice, so only retry when the server refused outright
+	var resp enrollmentResponse
+	err := c.Retry.do(context.Background(), isThrottled, func() error {
+		return c.postOnce(enrollmentPath, body, &resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Output.Voice == "" {
+		return nil, fmt.Errorf("no voice in response (request %s)", resp.RequestID)
+	}
+
+	model := resp.Output.TargetModel
+	if model == "" {
+		model = ModelVCRealtime
+	}
+	return &EnrollResult{VoiceID: resp.Output.Voice, TargetModel: model, RequestID: resp.RequestID}, nil
+}
+
+// ListVoices returns one page of enrolled custom voices. Pages start at 0.
+func (c *Client) ListVoices(page, pageSize int) (*VoicePage, error) {
+	body := map[string]any{
+		"model": ModelEnrollment,
+		"input": map[string]any{
+			"action":     "list",
+			"page_size":  pageSize,
+			"page_index": page,
+		},
+	}
+
+	var resp enrollmentResponse
+	if err := c.post(enrollmentPath, body, &resp); err != nil {
+		return nil, err
+	}
+	return &VoicePage{
+		Voices:    resp.Output.VoiceList,
+		Page:      page,
+		PageSize:  pageSize,
+		RequestID: resp.RequestID,
+	}, nil
+}
+
+// AllVoices iterates over every enrolled voice, fetching pages of pageSize
+// as needed. Iteration stops after the first error.
+func (c *Client) AllVoices(pageSize int) iter.Seq2[Voice, error] {
+	if pageSize <= 0 {
+		pageSize = 50
+	}
+	return func(yield func(Voice, error) bool) {
+		// The server may cap the page size below pageSize, so a short page
+		// doesn't mean the end: stop at a page with nothing new on it (empty,
+		// or repeated by a server that ignores the index)
+		seen := map[string]bool{}
+		for page := 0; ; page++ {
+			p, err := c.ListVoices(page, pageSize)
+			if err != nil {
+				yield(Voice{}, err)
+				return
+			}
+			fresh := false
+			for _, v := range p.Voices {
+				if seen[v.ID] {
+					continue
+				}
+				seen[v.ID] = true
+				fresh = true
+				if !yield(v, nil) {
+					return
+				}
+			}
+			if !fresh {
+				return
+			}
+		}
+	}
+}
+
+// DeleteVoice removes an enrolled voice
+func (c *Client) DeleteVoice(voiceID string) (*DeleteResult, error) {
+	body := map[string]any{
+		"model": ModelEnrollment,
+		"input": map[string]any{
+			"action": "delete",
+			"voice":  voiceID,
+		},
+	}
+
+	var resp enrollmentResponse
+	if err := c.post(enrollmentPath, body, &resp); err != nil {
+		return nil, err
+	}
+	return &DeleteResult{VoiceID: voiceID, RequestID: resp.RequestID}, nil
+}
+
+// parseAPITime parses DashScope timestamps, which are UTC+8 wall-clock time
+// without a zone in both regions. Returns the zero time if s is empty or
+// malformed.
+func parseAPITime(s string) time.Time {
+	if s == "" {
+		return time.Time{}
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04:05", s, utc8); err == nil {
+		return t
+	}
+	return time.Time{}
+}
+
+var utc8 = time.FixedZone("UTC+8", 8*60*60)
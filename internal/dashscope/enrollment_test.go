@@ -0,0 +1,139 @@
+package dashscope
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// enrollmentRequest is the body the fake API decodes
+type enrollmentRequest struct {
+	Model string `json:"model"`
+	Input struct {
+		Action    string `json:"action"`
+		PageSize  int    `json:"page_size"`
+		PageIndex int    `json:"page_index"`
+		Voice     string `json:"voice"`
+	} `json:"input"`
+}
+
+// fakeAPI serves the HTTP API with handle and counts requests
+func fakeAPI(t *testing.T, handle func(w http.ResponseWriter, req enrollmentRequest)) (*Client, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/api/v1"+enrollmentPath || r.Header.Get("Authorization") != "Bearer key" {
+			http.Error(w, `{"code":"NotFound"}`, http.StatusNotFound)
+			return
+		}
+		var req enrollmentRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		handle(w, req)
+	}))
+	t.Cleanup(srv.Close)
+	c := NewClient("key", Endpoints{HTTP: srv.URL + "/api/v1"})
+	c.Retry = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	return c, &requests
+}
+
+// voicePage writes a list response with voices ids[from:to]
+func voicePage(w http.ResponseWriter, ids []string, from, to int) {
+	from, to = min(from, len(ids)), min(to, len(ids))
+	var list []map[string]string
+	for _, id := range ids[from:to] {
+		list = append(list, map[string]string{"voice": id, "gmt_create": "2026-01-15 10:30:00"})
+	}
+	json.NewEncoder(w).Encode(map[string]any{"request_id": "r", "output": map[string]any{"voice_list": list}})
+}
+
+func collect(t *testing.T, c *Client, pageSize int) ([]string, error) {
+	t.Helper()
+	var ids []string
+	for v, err := range c.AllVoices(pageSize) {
+		if err != nil {
+			return ids, err
+		}
+		ids = append(ids, v.ID)
+	}
+	return ids, nil
+}
+
+var fiveVoices = []string{"v1", "v2", "v3", "v4", "v5"}
+
+func TestAllVoicesCappedPageSize(t *testing.T) {
+	// The server returns at most 2 per page whatever was asked for, so a
+	// short page isn't the end
+	c, requests := fakeAPI(t, func(w http.ResponseWriter, req enrollmentRequest) {
+		voicePage(w, fiveVoices, req.Input.PageIndex*2, req.Input.PageIndex*2+2)
+	})
+	ids, err := collect(t, c, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(ids, fiveVoices) {
+		t.Errorf("got %v, want %v", ids, fiveVoices)
+	}
+	if got := requests.Load(); got != 4 {
+		t.Errorf("made %d requests, want 3 pages and an empty one", got)
+	}
+}
+
+func TestAllVoicesIgnoredPageIndex(t *testing.T) {
+	// A server that ignores page_index repeats the first page forever
+	c, requests := fakeAPI(t, func(w http.ResponseWriter, req enrollmentRequest) {
+		voicePage(w, fiveVoices, 0, 2)
+	})
+	ids, err := collect(t, c, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(ids, fiveVoices[:2]) {
+		t.Errorf("got %v, want each voice once", ids)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("made %d requests, want to stop at the first repeated page", got)
+	}
+}
+
+func TestAllVoicesError(t *testing.T) {
+	c, _ := fakeAPI(t, func(w http.ResponseWriter, req enrollmentRequest) {
+		if req.Input.PageIndex > 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"code":"InvalidParameter","message":"bad page"}`)
+			return
+		}
+		voicePage(w, fiveVoices, 0, 2)
+	})
+	ids, err := collect(t, c, 2)
+	if err == nil {
+		t.Fatal("no error from a failed page")
+	}
+	if !slices.Equal(ids, fiveVoices[:2]) {
+		t.Errorf("got %v before the error, want the first page", ids)
+	}
+}
+
+func TestVoiceTimes(t *testing.T) {
+	c, _ := fakeAPI(t, func(w http.ResponseWriter, req enrollmentRequest) {
+		voicePage(w, fiveVoices, 0, 1)
+	})
+	page, err := c.ListVoices(0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Voices) != 1 {
+		t.Fatalf("got %d voices", len(page.Voices))
+	}
+	if got := page.Voices[0].CreatedAt; got.IsZero() || got.Year() != 2026 || got.Minute() != 30 {
+		t.Errorf("CreatedAt = %v, want 2026-01-15 10:30:00", got)
+	}
+}
@@ -0,0 +1,175 @@
+package dashscope
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+// APIError is an error reported by the DashScope API, over HTTP or WebSocket
+type APIError struct {
+	StatusCode int    // HTTP status, 0 for WebSocket errors
+	Code       string // e.g. "Throttling.RateQuota", "InvalidApiKey"
+	Message    string
+	RequestID  string // request_id (HTTP) or event_id (WebSocket)
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	b.WriteString("dashscope")
+	if e.Code != "" {
+		b.WriteString(": " + e.Code)
+	}
+	if e.Message != "" {
+		b.WriteString(": " + e.Message)
+	}
+	var meta []string
+	if e.StatusCode != 0 {
+		meta = append(meta, fmt.Sprintf("HTTP %d", e.StatusCode))
+	}
+	if e.RequestID != "" {
+		meta = append(meta, "request "+e.RequestID)
+	}
+	if len(meta) > 0 {
+		b.WriteString(" (" + strings.Join(meta, ", ") + ")")
+	}
+	return b.String()
+}
+
+// Throttled reports a rate or quota limit
+func (e *APIError) Throttled() bool {
+	return e.StatusCode == http.StatusTooManyRequests || strings.HasPrefix(e.Code, "Throttling")
+}
+
+// Unauthorized reports a missing, invalid or expired API key
+func (e *APIError) Unauthorized() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden ||
+		strings.Contains(e.Code, "ApiKey") || strings.Contains(e.Code, "AccessDenied")
+}
+
+// Temporary reports whether the same request may succeed if retried
+func (e *APIError) Temporary() bool {
+	if e.Throttled() || e.StatusCode >= 500 {
+		return true
+	}
+	switch e.Code {
+	case "InternalError", "InternalError.Algo", "ServiceUnavailable", "RequestTimeOut", "server_error":
+		return true
+	}
+	return false
+}
+
+// parseHTTPError builds an APIError from a non-200 response body
+func parseHTTPError(status int, body []byte) error {
+	var raw struct {
+		Code      string `json:"code"`
+		Message   string `json:"message"`
+		RequestID string `json:"request_id"`
+	}
+	e := &APIError{StatusCode: status}
+	if json.Unmarshal(body, &raw) == nil {
+		e.Code, e.Message, e.RequestID = raw.Code, raw.Message, raw.RequestID
+	}
+	if e.Message == "" && e.Code == "" {
+		e.Message = strings.TrimSpace(string(body))
+	}
+	return e
+}
+
+// parseServerError builds an APIError from a realtime "error" event
+func parseServerError(data []byte) error {
+	var raw struct {
+		EventID string `json:"event_id"`
+		Error   struct {
+			Type    string `json:"type"`
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return &APIError{Message: string(data)}
+	}
+	code := raw.Error.Code
+	if code == "" {
+		code = raw.Error.Type
+	}
+	return &APIError{Code: code, Message: raw.Error.Message, RequestID: raw.EventID}
+}
+
+// RetryPolicy controls retries of transient failures with jittered
+// exponential backoff
+type RetryPolicy struct {
+	MaxAttempts int           // total tries; 1 disables retries
+	BaseDelay   time.Duration // backoff before the second try
+	MaxDelay    time.Duration // backoff cap
+}
+
+// DefaultRetry tries three times, backing off about 0.5s then 1s
+var DefaultRetry = RetryPolicy{MaxAttempts: 3, BaseDelay: 500 * time.Millisecond, MaxDelay: 8 * time.Second}
+
+// backoff returns the wait before retry n (1-based), with full jitter over
+// the upper half so retries don't fire back to back
+func (p RetryPolicy) backoff(n int) time.Duration {
+	d := p.BaseDelay << (n - 1)
+	if d <= 0 || d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// do runs fn until it succeeds, fails permanently, or attempts run out.
+// retryable decides which errors are worth another try.
+func (p RetryPolicy) do(ctx context.Context, retryable func(error) bool, fn func() error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn()
+		if err == nil || attempt >= p.MaxAttempts || !retryable(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(p.backoff(attempt)):
+		}
+	}
+}
+
+// IsTemporary reports whether err is a transient API or network failure
+func IsTemporary(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, errSessionBroken) {
+		return true
+	}
+	switch websocket.CloseStatus(err) {
+	case websocket.StatusAbnormalClosure, websocket.StatusGoingAway, websocket.StatusInternalError,
+		websocket.StatusServiceRestart, websocket.StatusTryAgainLater:
+		return true
+	}
+	return false
+}
+
+// isThrottled limits retries of non-idempotent requests to rate limits,
+// which the server rejects before doing any work
+func isThrottled(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Throttled()
+}
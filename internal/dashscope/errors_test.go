@@ -0,0 +1,189 @@
+package dashscope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+func TestIsTemporary(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"429", &APIError{StatusCode: 429}, true},
+		{"throttling code", &APIError{StatusCode: 400, Code: "Throttling.RateQuota"}, true},
+		{"500", &APIError{StatusCode: 500}, true},
+		{"internal error event", &APIError{Code: "InternalError"}, true},
+		{"bad request", &APIError{StatusCode: 400, Code: "InvalidParameter"}, false},
+		{"bad key", &APIError{StatusCode: 401, Code: "InvalidApiKey"}, false},
+		{"wrapped", fmt.Errorf("read: %w", &APIError{StatusCode: 503}), true},
+		{"network", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{"eof", fmt.Errorf("read: %w", io.ErrUnexpectedEOF), true},
+		{"session closed", errSessionBroken, true},
+		{"going away", websocket.CloseError{Code: websocket.StatusGoingAway}, true},
+		{"policy violation", websocket.CloseError{Code: websocket.StatusPolicyViolation}, false},
+		{"canceled", context.Canceled, false},
+		{"deadline", fmt.Errorf("read: %w", context.DeadlineExceeded), false},
+		{"other", errors.New("no voice in response"), false},
+	}
+	for _, tt := range tests {
+		if got := IsTemporary(tt.err); got != tt.want {
+			t.Errorf("%s: IsTemporary(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestParseHTTPError(t *testing.T) {
+	err := parseHTTPError(429, []byte(`{"code":"Throttling.RateQuota","message":"slow down","request_id":"abc"}`))
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !apiErr.Throttled() || apiErr.RequestID != "abc" {
+		t.Fatalf("got %#v", err)
+	}
+	if want := "dashscope: Throttling.RateQuota: slow down (HTTP 429, request abc)"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+
+	err = parseHTTPError(502, []byte("Bad Gateway\n"))
+	if !errors.As(err, &apiErr) || apiErr.Message != "Bad Gateway" || !apiErr.Temporary() {
+		t.Errorf("plain body: got %#v", err)
+	}
+}
+
+func TestParseServerError(t *testing.T) {
+	err := parseServerError([]byte(`{"event_id":"e1","type":"error","error":{"type":"invalid_request_error","code":"InvalidParameter","message":"bad voice"}}`))
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "InvalidParameter" || apiErr.RequestID != "e1" || apiErr.Temporary() {
+		t.Errorf("got %#v", err)
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}
+	temporary := &APIError{StatusCode: 503}
+
+	tests := []struct {
+		name     string
+		failures int   // attempts that fail before success
+		err      error // what they fail with
+		attempts int
+		wantErr  bool
+	}{
+		{"success", 0, nil, 1, false},
+		{"recovers", 2, temporary, 3, false},
+		{"gives up", 5, temporary, 3, true},
+		{"permanent", 5, &APIError{StatusCode: 400}, 1, true},
+	}
+	for _, tt := range tests {
+		attempts := 0
+		err := policy.do(context.Background(), IsTemporary, func() error {
+			attempts++
+			if attempts <= tt.failures {
+				return tt.err
+			}
+			return nil
+		})
+		if attempts != tt.attempts || (err != nil) != tt.wantErr {
+			t.Errorf("%s: %d attempts, err %v; want %d attempts, error %v", tt.name, attempts, err, tt.attempts, tt.wantErr)
+		}
+	}
+}
+
+func TestRetryCanceled(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour, MaxDelay: time.Hour}
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	done := make(chan error)
+	go func() {
+		done <- policy.do(ctx, IsTemporary, func() error {
+			attempts++
+			return &APIError{StatusCode: 503}
+		})
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		if err == nil || attempts != 1 {
+			t.Errorf("got %v after %d attempts, want the first error", err, attempts)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("backoff didn't stop on cancel")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	for n, full := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 300 * time.Millisecond, 10: 300 * time.Millisecond, 70: 300 * time.Millisecond} {
+		for range 50 {
+			if d := p.backoff(n); d < full/2 || d > full {
+				t.Fatalf("backoff(%d) = %s, want %s-%s", n, d, full/2, full)
+			}
+		}
+	}
+}
+
+func TestEnrollVoiceRetriesOnlyThrottling(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		code     string
+		requests int32
+		wantErr  bool
+	}{
+		{"throttled", http.StatusTooManyRequests, "Throttling.RateQuota", 2, false},
+		// The voice may have been created before the server failed, so a
+		// retry could enroll it twice
+		{"server error", http.StatusInternalServerError, "InternalError", 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			c, requests := fakeAPI(t, func(w http.ResponseWriter, req enrollmentRequest) {
+				if calls.Add(1) == 1 {
+					w.WriteHeader(tt.status)
+					fmt.Fprintf(w, `{"code":%q}`, tt.code)
+					return
+				}
+				fmt.Fprint(w, `{"request_id":"r","output":{"voice":"vox-me","target_model":"m"}}`)
+			})
+			res, err := c.EnrollVoice("me", "AAAA")
+			if got := requests.Load(); got != tt.requests {
+				t.Errorf("made %d requests, want %d", got, tt.requests)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && res.VoiceID != "vox-me" {
+				t.Errorf("voice = %q", res.VoiceID)
+			}
+		})
+	}
+}
+
+func TestPostRetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	c, requests := fakeAPI(t, func(w http.ResponseWriter, req enrollmentRequest) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		voicePage(w, fiveVoices, 0, 1)
+	})
+	if _, err := c.ListVoices(0, 10); err != nil {
+		t.Fatal(err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("made %d requests, want one retry", got)
+	}
+}
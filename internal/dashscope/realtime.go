@@ -6,12 +6,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/coder/websocket"
+	"github.com/ontypehq/vox/internal/segment"
 )
 
 const (
-	wsEndpoint            = "wss://dashscope.aliyuncs.com/api-ws/v1/realtime"
 	ModelFlashRealtime    = "qwen3-tts-flash-realtime"
 	ModelInstructRealtime = "qwen3-tts-instruct-flash-realtime"
 	ModelVCRealtime       = "qwen3-tts-vc-realtime-2026-01-15"
@@ -26,15 +27,19 @@ type TTSOptions struct {
 	Lang       string
 	Instruct   string
 	SpeechRate float64
+	Volume     int     // 1-100, 0 = default (50)
+	PitchRate  float64 // 0.5-2.0, 0 = default (1.0)
 }
 
 // RealtimeClient handles WebSocket streaming TTS
 type RealtimeClient struct {
-	apiKey string
+	apiKey   string
+	endpoint string
+	Retry    RetryPolicy
 }
 
-func NewRealtimeClient(apiKey string) *RealtimeClient {
-	return &RealtimeClient{apiKey: apiKey}
+func NewRealtimeClient(apiKey string, ep Endpoints) *RealtimeClient {
+	return &RealtimeClient{apiKey: apiKey, endpoint: ep.WS, Retry: DefaultRetry}
 }
 
 type wsMessage struct {
@@ -74,24 +79,131 @@ type serverMessage struct {
 }
 
 // StreamTTS opens a WebSocket, sends text, and streams PCM audio chunks via callback.
+// Transient failures are retried as long as no audio has been delivered yet.
 func (rc *RealtimeClient) StreamTTS(ctx context.Context, opts TTSOptions, onAudio func([]byte)) error {
-	url := fmt.Sprintf("%s?model=%s", wsEndpoint, opts.Model)
-
-	conn, _, err := websocket.Dial(ctx, url, &websocket.DialOptions{
-		HTTPHeader: http.Header{
-			"Authorization": []string{"Bearer " + rc.apiKey},
-		},
+	var delivered bool
+	deliver := func(pcm []byte) {
+		delivered = true
+		onAudio(pcm)
+	}
+	retryable := func(err error) bool { return !delivered && IsTemporary(err) }
+	return rc.Retry.do(ctx, retryable, func() error {
+		return rc.streamTTSOnce(ctx, opts, deliver)
 	})
+}
+
+func (rc *RealtimeClient) streamTTSOnce(ctx context.Context, opts TTSOptions, onAudio func([]byte)) error {
+	conn, _, err := rc.openTTS(ctx, opts, "server_commit")
 	if err != nil {
-		return fmt.Errorf("websocket dial: %w", err)
+		return err
 	}
 	defer conn.CloseNow()
-	conn.SetReadLimit(1 << 20) // 1MB
 
-	if err := rc.expectMessage(ctx, conn, "session.created"); err != nil {
+	appendMsg := textAppend{Type: "input_text_buffer.append", Text: opts.Text}
+	if err := rc.writeJSON(ctx, conn, appendMsg); err != nil {
+		return fmt.Errorf("text append: %w", err)
+	}
+
+	finish := wsMessage{Type: "session.finish"}
+	if err := rc.writeJSON(ctx, conn, finish); err != nil {
+		return fmt.Errorf("session.finish: %w", err)
+	}
+
+	return rc.readAudio(ctx, conn, onAudio)
+}
+
+// StreamTTSInput synthesizes text that arrives incrementally, e.g. tokens
+// from an LLM. Text is appended as it arrives and committed at each sentence
+// boundary, so audio for the first sentence starts while the rest is still
+// being generated. opts.Text is ignored. Returns once text is closed and
+// all audio has been delivered. Not retried, since input is consumed as it
+// is sent.
+func (rc *RealtimeClient) StreamTTSInput(ctx context.Context, opts TTSOptions, text <-chan string, onAudio func([]byte)) error {
+	conn, _, err := rc.openTTS(ctx, opts, "commit")
+	if err != nil {
+		return err
+	}
+	defer conn.CloseNow()
+
+	writeErr := make(chan error, 1)
+	go func() {
+		writeErr <- rc.feedText(ctx, conn, text)
+	}()
+
+	err = rc.readAudio(ctx, conn, onAudio)
+	if err != nil {
+		select {
+		case werr := <-writeErr:
+			if werr != nil {
+				return werr
+			}
+		default:
+		}
+	}
+	return err
+}
+
+// maxPendingText forces a commit when a producer emits a long run of text
+// without sentence punctuation
+const maxPendingText = 600
+
+// feedText appends text as it arrives, commits it sentence by sentence, and
+// finishes the session once the channel closes
+func (rc *RealtimeClient) feedText(ctx context.Context, conn *websocket.Conn, text <-chan string) error {
+	var pending string
+	commit := func(s string) error {
+		if strings.TrimSpace(s) == "" {
+			return nil
+		}
+		if err := rc.writeJSON(ctx, conn, textAppend{Type: "input_text_buffer.append", Text: s}); err != nil {
+			return fmt.Errorf("text append: %w", err)
+		}
+		if err := rc.writeJSON(ctx, conn, wsMessage{Type: "input_text_buffer.commit"}); err != nil {
+			return fmt.Errorf("text commit: %w", err)
+		}
+		return nil
+	}
+
+	for chunk := range text {
+		pending += chunk
+		cut := segment.LastSentenceEnd(pending)
+		if cut == 0 && len(pending) > maxPendingText {
+			cut = len(pending)
+		}
+		if cut > 0 {
+			if err := commit(pending[:cut]); err != nil {
+				return err
+			}
+			pending = pending[cut:]
+		}
+	}
+	if err := commit(pending); err != nil {
 		return err
 	}
 
+	if err := rc.writeJSON(ctx, conn, wsMessage{Type: "session.finish"}); err != nil {
+		return fmt.Errorf("session.finish: %w", err)
+	}
+	return nil
+}
+
+// openTTS dials the realtime endpoint and configures a synthesis session
+func (rc *RealtimeClient) openTTS(ctx context.Context, opts TTSOptions, mode string) (*websocket.Conn, sessionParams, error) {
+	conn, err := rc.dial(ctx, opts.Model)
+	if err != nil {
+		return nil, sessionParams{}, err
+	}
+
+	session := sessionParamsFor(opts, mode)
+	update := sessionUpdate{Type: "session.update", Session: session}
+	if err := rc.writeJSON(ctx, conn, update); err != nil {
+		conn.CloseNow()
+		return nil, sessionParams{}, fmt.Errorf("session.update: %w", err)
+	}
+	return conn, session, nil
+}
+
+func sessionParamsFor(opts TTSOptions, mode string) sessionParams {
 	langType := "auto"
 	if opts.Lang != "" {
 		langType = opts.Lang
@@ -100,37 +212,55 @@ func (rc *RealtimeClient) StreamTTS(ctx context.Context, opts TTSOptions, onAudi
 	if speechRate == 0 {
 		speechRate = 1.0
 	}
+	volume := opts.Volume
+	if volume == 0 {
+		volume = 50
+	}
+	pitchRate := opts.PitchRate
+	if pitchRate == 0 {
+		pitchRate = 1.0
+	}
 
 	session := sessionParams{
 		Voice:          opts.Voice,
 		ResponseFormat: "pcm",
 		SampleRate:     24000,
-		Mode:           "server_commit",
+		Mode:           mode,
 		LanguageType:   langType,
-		Volume:         50,
+		Volume:         volume,
 		SpeechRate:     speechRate,
-		PitchRate:      1.0,
+		PitchRate:      pitchRate,
 	}
 	if opts.Instruct != "" {
 		session.Instructions = opts.Instruct
 		session.OptimizeInstructions = true
 	}
+	return session
+}
 
-	update := sessionUpdate{Type: "session.update", Session: session}
-	if err := rc.writeJSON(ctx, conn, update); err != nil {
-		return fmt.Errorf("session.update: %w", err)
-	}
+// dial opens a realtime WebSocket for model and waits for session.created
+func (rc *RealtimeClient) dial(ctx context.Context, model string) (*websocket.Conn, error) {
+	url := fmt.Sprintf("%s?model=%s", rc.endpoint, model)
 
-	appendMsg := textAppend{Type: "input_text_buffer.append", Text: opts.Text}
-	if err := rc.writeJSON(ctx, conn, appendMsg); err != nil {
-		return fmt.Errorf("text append: %w", err)
+	conn, _, err := websocket.Dial(ctx, url, &websocket.DialOptions{
+		HTTPHeader: http.Header{
+			"Authorization": []string{"Bearer " + rc.apiKey},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("websocket dial: %w", err)
 	}
+	conn.SetReadLimit(1 << 20) // 1MB
 
-	finish := wsMessage{Type: "session.finish"}
-	if err := rc.writeJSON(ctx, conn, finish); err != nil {
-		return fmt.Errorf("session.finish: %w", err)
+	if err := rc.expectMessage(ctx, conn, "session.created"); err != nil {
+		conn.CloseNow()
+		return nil, err
 	}
+	return conn, nil
+}
 
+// readAudio delivers audio deltas until the server finishes the session
+func (rc *RealtimeClient) readAudio(ctx context.Context, conn *websocket.Conn, onAudio func([]byte)) error {
 	for {
 		_, data, err := conn.Read(ctx)
 		if err != nil {
@@ -158,7 +288,7 @@ func (rc *RealtimeClient) StreamTTS(ctx context.Context, opts TTSOptions, onAudi
 			return nil
 
 		case "error":
-			return fmt.Errorf("server error: %s", string(data))
+			return parseServerError(data)
 		}
 	}
 }
@@ -0,0 +1,191 @@
+package dashscope
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/ontypehq/vox/internal/segment"
+)
+
+const ModelASRRealtime = "qwen3-asr-flash-realtime"
+
+// ASRStreamOptions holds parameters for a realtime transcription session
+type ASRStreamOptions struct {
+	Model      string
+	Lang       string // optional language hint (zh, en, ...)
+	Context    string // optional corpus text to bias recognition
+	SampleRate int    // PCM input rate, 16000 if zero
+	// TurnSilence is the pause that ends a turn, 800ms if zero. Each turn
+	// becomes one timed segment of the result.
+	TurnSilence time.Duration
+}
+
+type asrSessionUpdate struct {
+	Type    string           `json:"type"`
+	Session asrSessionParams `json:"session"`
+}
+
+type asrSessionParams struct {
+	Modalities         []string          `json:"modalities"`
+	InputAudioFormat   string            `json:"input_audio_format"`
+	SampleRate         int               `json:"sample_rate"`
+	InputTranscription asrTranscription  `json:"input_audio_transcription"`
+	TurnDetection      *asrTurnDetection `json:"turn_detection"`
+}
+
+type asrTranscription struct {
+	Language string     `json:"language,omitempty"`
+	Corpus   *asrCorpus `json:"corpus,omitempty"`
+}
+
+type asrCorpus struct {
+	Text string `json:"text"`
+}
+
+type asrTurnDetection struct {
+	Type              string  `json:"type"`
+	Threshold         float64 `json:"threshold"`
+	SilenceDurationMS int     `json:"silence_duration_ms"`
+}
+
+type audioAppend struct {
+	Type  string `json:"type"`
+	Audio string `json:"audio"`
+}
+
+type asrServerMessage struct {
+	Type         string `json:"type"`
+	ItemID       string `json:"item_id,omitempty"`
+	Text         string `json:"text,omitempty"`
+	Stash        string `json:"stash,omitempty"`
+	Transcript   string `json:"transcript,omitempty"`
+	AudioStartMS *int   `json:"audio_start_ms,omitempty"`
+	AudioEndMS   *int   `json:"audio_end_ms,omitempty"`
+}
+
+// StreamASR opens a realtime transcription session and sends 16-bit mono PCM
+// chunks from audio until the channel is closed. Interim hypotheses for the
+// whole utterance so far are reported via onPartial; the final text of every
+// completed turn is returned once the server finishes the session, along
+// with where in the audio each turn was spoken when the server says so.
+func (rc *RealtimeClient) StreamASR(ctx context.Context, opts ASRStreamOptions, audio <-chan []byte, onPartial func(string)) (*ASRResult, error) {
+	model := opts.Model
+	if model == "" {
+		model = ModelASRRealtime
+	}
+	sampleRate := opts.SampleRate
+	if sampleRate == 0 {
+		sampleRate = 16000
+	}
+	turnSilence := opts.TurnSilence
+	if turnSilence == 0 {
+		turnSilence = 800 * time.Millisecond
+	}
+
+	conn, err := rc.dial(ctx, model)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.CloseNow()
+
+	session := asrSessionParams{
+		Modalities:       []string{"text"},
+		InputAudioFormat: "pcm",
+		SampleRate:       sampleRate,
+		InputTranscription: asrTranscription{
+			Language: opts.Lang,
+		},
+		// Server VAD splits long dictation into turns, each finalized on its own
+		TurnDetection: &asrTurnDetection{Type: "server_vad", Threshold: 0.2, SilenceDurationMS: int(turnSilence / time.Millisecond)},
+	}
+	if opts.Context != "" {
+		session.InputTranscription.Corpus = &asrCorpus{Text: opts.Context}
+	}
+	if err := rc.writeJSON(ctx, conn, asrSessionUpdate{Type: "session.update", Session: session}); err != nil {
+		return nil, fmt.Errorf("session.update: %w", err)
+	}
+
+	// Writer: forward audio, then finish the session once input ends
+	writeErr := make(chan error, 1)
+	go func() {
+		for pcm := range audio {
+			msg := audioAppend{Type: "input_audio_buffer.append", Audio: base64.StdEncoding.EncodeToString(pcm)}
+			if err := rc.writeJSON(ctx, conn, msg); err != nil {
+				writeErr <- fmt.Errorf("audio append: %w", err)
+				return
+			}
+		}
+		if err := rc.writeJSON(ctx, conn, wsMessage{Type: "session.finish"}); err != nil {
+			writeErr <- fmt.Errorf("session.finish: %w", err)
+		}
+	}()
+
+	var done []string
+	var segments []ASRSegment
+	// Turn boundaries by item, from the server's VAD events
+	starts := map[string]time.Duration{}
+	ends := map[string]time.Duration{}
+	for {
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			select {
+			case werr := <-writeErr:
+				return nil, werr
+			default:
+			}
+			return nil, fmt.Errorf("read: %w", err)
+		}
+
+		var msg asrServerMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			continue
+		}
+
+		switch msg.Type {
+		case "input_audio_buffer.speech_started":
+			if msg.AudioStartMS != nil {
+				starts[msg.ItemID] = time.Duration(*msg.AudioStartMS) * time.Millisecond
+			}
+
+		case "input_audio_buffer.speech_stopped":
+			if msg.AudioEndMS != nil {
+				ends[msg.ItemID] = time.Duration(*msg.AudioEndMS) * time.Millisecond
+			}
+
+		case "conversation.item.input_audio_transcription.text":
+			if onPartial != nil {
+				onPartial(segment.Join(append(done, msg.Text+msg.Stash)))
+			}
+
+		case "conversation.item.input_audio_transcription.completed":
+			if t := strings.TrimSpace(msg.Transcript); t != "" {
+				done = append(done, t)
+				start, started := starts[msg.ItemID]
+				end, stopped := ends[msg.ItemID]
+				if started && stopped {
+					segments = append(segments, ASRSegment{Start: start, End: end, Text: t})
+				}
+			}
+			if onPartial != nil {
+				onPartial(segment.Join(done))
+			}
+
+		case "session.finished":
+			conn.Close(websocket.StatusNormalClosure, "done")
+			// Timing is all or nothing, so a caller never gets captions with
+			// gaps where text went missing
+			if len(segments) != len(done) {
+				segments = nil
+			}
+			return &ASRResult{Text: segment.Join(done), Segments: segments}, nil
+
+		case "error":
+			return nil, parseServerError(data)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package dashscope
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+)
+
+// fakeRealtime serves the realtime WebSocket API: it sends session.created
+// and hands each connection to serve
+func fakeRealtime(t *testing.T, serve func(ctx context.Context, conn *websocket.Conn)) *RealtimeClient {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.CloseNow()
+		ctx := r.Context()
+		if err := wsjson.Write(ctx, conn, wsMessage{Type: "session.created"}); err != nil {
+			return
+		}
+		serve(ctx, conn)
+	}))
+	t.Cleanup(srv.Close)
+	rc := NewRealtimeClient("key", Endpoints{WS: "ws" + strings.TrimPrefix(srv.URL, "http")})
+	rc.Retry = RetryPolicy{MaxAttempts: 1}
+	return rc
+}
+
+// asrTurn is one turn the fake ASR server reports, without timing if start
+// is negative
+type asrTurn struct {
+	start, end int
+	text       string
+}
+
+// serveASR reads a transcription session until session.finish, then
+// reports turns. The session.update is stored in update.
+func serveASR(update *asrSessionUpdate, turns []asrTurn) func(ctx context.Context, conn *websocket.Conn) {
+	return func(ctx context.Context, conn *websocket.Conn) {
+		if err := wsjson.Read(ctx, conn, update); err != nil {
+			return
+		}
+		for {
+			var msg wsMessage
+			if err := wsjson.Read(ctx, conn, &msg); err != nil {
+				return
+			}
+			if msg.Type == "session.finish" {
+				break
+			}
+		}
+		for i, turn := range turns {
+			item := string(rune('a' + i))
+			if turn.start >= 0 {
+				wsjson.Write(ctx, conn, map[string]any{"type": "input_audio_buffer.speech_started", "item_id": item, "audio_start_ms": turn.start})
+				wsjson.Write(ctx, conn, map[string]any{"type": "input_audio_buffer.speech_stopped", "item_id": item, "audio_end_ms": turn.end})
+			}
+			wsjson.Write(ctx, conn, map[string]any{"type": "conversation.item.input_audio_transcription.completed", "item_id": item, "transcript": turn.text})
+		}
+		wsjson.Write(ctx, conn, wsMessage{Type: "session.finished"})
+		conn.Read(ctx)
+	}
+}
+
+func streamChunks(chunks ...[]byte) <-chan []byte {
+	ch := make(chan []byte, len(chunks))
+	for _, c := range chunks {
+		ch <- c
+	}
+	close(ch)
+	return ch
+}
+
+func TestStreamASRSegments(t *testing.T) {
+	var update asrSessionUpdate
+	rc := fakeRealtime(t, serveASR(&update, []asrTurn{
+		{500, 1800, "Hello there."},
+		{2400, 3000, "Bye."},
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	opts := ASRStreamOptions{TurnSilence: 400 * time.Millisecond}
+	result, err := rc.StreamASR(ctx, opts, streamChunks(make([]byte, 3200), make([]byte, 3200)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := update.Session.TurnDetection.SilenceDurationMS; got != 400 {
+		t.Errorf("silence_duration_ms = %d, want 400", got)
+	}
+	if result.Text != "Hello there. Bye." {
+		t.Errorf("text = %q", result.Text)
+	}
+	want := []ASRSegment{
+		{Start: 500 * time.Millisecond, End: 1800 * time.Millisecond, Text: "Hello there."},
+		{Start: 2400 * time.Millisecond, End: 3 * time.Second, Text: "Bye."},
+	}
+	if !slices.Equal(result.Segments, want) {
+		t.Errorf("segments = %v, want %v", result.Segments, want)
+	}
+}
+
+func TestStreamASRPartialTiming(t *testing.T) {
+	var update asrSessionUpdate
+	rc := fakeRealtime(t, serveASR(&update, []asrTurn{
+		{500, 1800, "Hello there."},
+		{-1, -1, "Bye."},
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	result, err := rc.StreamASR(ctx, ASRStreamOptions{}, streamChunks(make([]byte, 3200)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := update.Session.TurnDetection.SilenceDurationMS; got != 800 {
+		t.Errorf("default silence_duration_ms = %d, want 800", got)
+	}
+	if result.Text != "Hello there. Bye." || result.Segments != nil {
+		t.Errorf("got %q with segments %v, want the text and no timing", result.Text, result.Segments)
+	}
+}
@@ -0,0 +1,220 @@
+package dashscope
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+// Session is a long-lived realtime TTS connection for one model and voice.
+// It runs in commit mode, so many utterances can be synthesized over the
+// same WebSocket without repeating the handshake. Not safe for concurrent use.
+type Session struct {
+	rc       *RealtimeClient
+	conn     *websocket.Conn
+	model    string
+	voice    string
+	params   sessionParams
+	lastUsed time.Time
+	broken   bool
+}
+
+// OpenSession dials a realtime TTS session. opts.Text is ignored; the
+// remaining options become the session defaults.
+func (rc *RealtimeClient) OpenSession(ctx context.Context, opts TTSOptions) (*Session, error) {
+	conn, params, err := rc.openTTS(ctx, opts, "commit")
+	if err != nil {
+		return nil, err
+	}
+	return &Session{
+		rc:       rc,
+		conn:     conn,
+		model:    opts.Model,
+		voice:    opts.Voice,
+		params:   params,
+		lastUsed: time.Now(),
+	}, nil
+}
+
+// Synthesize speaks opts.Text and returns once its response is done.
+// opts.Model and opts.Voice must match the session. Other settings that
+// differ from the current ones are applied with a session.update first.
+func (s *Session) Synthesize(ctx context.Context, opts TTSOptions, onAudio func([]byte)) error {
+	if s.broken {
+		return errSessionBroken
+	}
+	if opts.Model != s.model || opts.Voice != s.voice {
+		return fmt.Errorf("session is for %s/%s, not %s/%s", s.model, s.voice, opts.Model, opts.Voice)
+	}
+	s.lastUsed = time.Now()
+
+	// Any failure from here leaves the conversation in an unknown state
+	s.broken = true
+
+	if params := sessionParamsFor(opts, "commit"); params != s.params {
+		update := sessionUpdate{Type: "session.update", Session: params}
+		if err := s.rc.writeJSON(ctx, s.conn, update); err != nil {
+			return fmt.Errorf("session.update: %w", err)
+		}
+		s.params = params
+	}
+
+	if err := s.rc.writeJSON(ctx, s.conn, textAppend{Type: "input_text_buffer.append", Text: opts.Text}); err != nil {
+		return fmt.Errorf("text append: %w", err)
+	}
+	if err := s.rc.writeJSON(ctx, s.conn, wsMessage{Type: "input_text_buffer.commit"}); err != nil {
+		return fmt.Errorf("text commit: %w", err)
+	}
+
+	for {
+		_, data, err := s.conn.Read(ctx)
+		if err != nil {
+			return fmt.Errorf("read: %w", err)
+		}
+
+		var msg serverMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			continue
+		}
+
+		switch msg.Type {
+		case "response.audio.delta":
+			pcm, err := base64.StdEncoding.DecodeString(msg.Delta)
+			if err != nil {
+				return fmt.Errorf("decode audio: %w", err)
+			}
+			onAudio(pcm)
+
+		case "response.done":
+			s.broken = false
+			s.lastUsed = time.Now()
+			return nil
+
+		case "session.finished":
+			return errSessionBroken
+
+		case "error":
+			return parseServerError(data)
+		}
+	}
+}
+
+// Close ends the session
+func (s *Session) Close() error {
+	s.broken = true
+	return s.conn.Close(websocket.StatusNormalClosure, "done")
+}
+
+var errSessionBroken = errors.New("session closed")
+
+// sessionIdleTimeout drops pooled sessions the server has likely timed out
+const sessionIdleTimeout = 2 * time.Minute
+
+// SessionPool keeps idle sessions per model and voice, re-dialing
+// transparently when the server has closed one. Safe for concurrent use.
+type SessionPool struct {
+	rc      *RealtimeClient
+	maxIdle int
+	mu      sync.Mutex
+	idle    map[string][]*Session
+	closed  bool
+}
+
+// NewSessionPool creates a pool that keeps up to maxIdle sessions per
+// model and voice
+func (rc *RealtimeClient) NewSessionPool(maxIdle int) *SessionPool {
+	if maxIdle < 1 {
+		maxIdle = 1
+	}
+	return &SessionPool{rc: rc, maxIdle: maxIdle, idle: map[string][]*Session{}}
+}
+
+// StreamTTS synthesizes opts.Text on a pooled session. If a reused session
+// turns out to be dead before any audio arrives, it is replaced at once with
+// a freshly dialed one; other transient failures before the first audio are
+// retried with the client's backoff policy.
+func (p *SessionPool) StreamTTS(ctx context.Context, opts TTSOptions, onAudio func([]byte)) error {
+	var delivered bool
+	deliver := func(pcm []byte) {
+		delivered = true
+		onAudio(pcm)
+	}
+	retryable := func(err error) bool { return !delivered && IsTemporary(err) }
+	return p.rc.Retry.do(ctx, retryable, func() error {
+		return p.streamOnce(ctx, opts, deliver, &delivered)
+	})
+}
+
+func (p *SessionPool) streamOnce(ctx context.Context, opts TTSOptions, deliver func([]byte), delivered *bool) error {
+	s, reused, err := p.get(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	err = s.Synthesize(ctx, opts, deliver)
+	if err != nil && reused && !*delivered && ctx.Err() == nil {
+		s.Close()
+		if s, err = p.rc.OpenSession(ctx, opts); err != nil {
+			return err
+		}
+		err = s.Synthesize(ctx, opts, deliver)
+	}
+	p.put(s)
+	return err
+}
+
+// Close shuts down all idle sessions. Sessions in use are closed when returned.
+func (p *SessionPool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	for key, sessions := range p.idle {
+		for _, s := range sessions {
+			s.Close()
+		}
+		delete(p.idle, key)
+	}
+	return nil
+}
+
+func (p *SessionPool) get(ctx context.Context, opts TTSOptions) (*Session, bool, error) {
+	key := opts.Model + "\x00" + opts.Voice
+
+	p.mu.Lock()
+	for len(p.idle[key]) > 0 {
+		sessions := p.idle[key]
+		s := sessions[len(sessions)-1]
+		p.idle[key] = sessions[:len(sessions)-1]
+		if time.Since(s.lastUsed) < sessionIdleTimeout {
+			p.mu.Unlock()
+			return s, true, nil
+		}
+		s.Close()
+	}
+	p.mu.Unlock()
+
+	s, err := p.rc.OpenSession(ctx, opts)
+	return s, false, err
+}
+
+func (p *SessionPool) put(s *Session) {
+	if s.broken {
+		s.conn.CloseNow()
+		return
+	}
+	key := s.model + "\x00" + s.voice
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed || len(p.idle[key]) >= p.maxIdle {
+		s.Close()
+		return
+	}
+	p.idle[key] = append(p.idle[key], s)
+}
@@ -0,0 +1,169 @@
+package dashscope
+
+import (
+	"context"
+	"encoding/base64"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+)
+
+// ttsServer is a fake realtime TTS endpoint in commit mode. Each commit
+// gets one audio delta and response.done. It counts connections and
+// session.update messages, and can hang up after a connection's first
+// response.
+type ttsServer struct {
+	dials       atomic.Int32
+	updates     atomic.Int32
+	hangUpAfter bool
+}
+
+func (s *ttsServer) serve(ctx context.Context, conn *websocket.Conn) {
+	s.dials.Add(1)
+	for {
+		var msg wsMessage
+		if err := wsjson.Read(ctx, conn, &msg); err != nil {
+			return
+		}
+		switch msg.Type {
+		case "session.update":
+			s.updates.Add(1)
+		case "input_text_buffer.commit":
+			wsjson.Write(ctx, conn, serverMessage{Type: "response.audio.delta", Delta: base64.StdEncoding.EncodeToString([]byte("pcm"))})
+			wsjson.Write(ctx, conn, serverMessage{Type: "response.done"})
+			if s.hangUpAfter {
+				conn.Close(websocket.StatusNormalClosure, "bye")
+				return
+			}
+		}
+	}
+}
+
+func synthesize(t *testing.T, pool *SessionPool, opts TTSOptions) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var audio []byte
+	if err := pool.StreamTTS(ctx, opts, func(pcm []byte) { audio = append(audio, pcm...) }); err != nil {
+		t.Fatal(err)
+	}
+	if string(audio) != "pcm" {
+		t.Fatalf("got audio %q, want %q", audio, "pcm")
+	}
+}
+
+func TestSessionPoolReuse(t *testing.T) {
+	srv := &ttsServer{}
+	pool := fakeRealtime(t, srv.serve).NewSessionPool(2)
+	defer pool.Close()
+
+	cherry := TTSOptions{Model: ModelFlashRealtime, Voice: "Cherry", Text: "one"}
+	synthesize(t, pool, cherry)
+	synthesize(t, pool, cherry)
+	if got := srv.dials.Load(); got != 1 {
+		t.Errorf("two utterances in one voice dialed %d times, want 1", got)
+	}
+	if got := srv.updates.Load(); got != 1 {
+		t.Errorf("sent %d session.update messages for unchanged settings, want 1", got)
+	}
+
+	// New settings are applied to the pooled session; a new voice needs a
+	// session of its own
+	faster := cherry
+	faster.SpeechRate = 1.5
+	synthesize(t, pool, faster)
+	if got := srv.updates.Load(); got != 2 {
+		t.Errorf("sent %d session.update messages after a speed change, want 2", got)
+	}
+	synthesize(t, pool, TTSOptions{Model: ModelFlashRealtime, Voice: "Ethan", Text: "two"})
+	if got := srv.dials.Load(); got != 2 {
+		t.Errorf("dialed %d times for two voices, want 2", got)
+	}
+}
+
+func TestSessionPoolEvictsIdle(t *testing.T) {
+	srv := &ttsServer{}
+	pool := fakeRealtime(t, srv.serve).NewSessionPool(2)
+	defer pool.Close()
+
+	opts := TTSOptions{Model: ModelFlashRealtime, Voice: "Cherry", Text: "one"}
+	synthesize(t, pool, opts)
+	pool.mu.Lock()
+	for _, s := range pool.idle[opts.Model+"\x00"+opts.Voice] {
+		s.lastUsed = time.Now().Add(-2 * sessionIdleTimeout)
+	}
+	pool.mu.Unlock()
+
+	synthesize(t, pool, opts)
+	if got := srv.dials.Load(); got != 2 {
+		t.Errorf("dialed %d times, want a fresh session for one idle past the timeout", got)
+	}
+}
+
+func TestSessionPoolMaxIdle(t *testing.T) {
+	srv := &ttsServer{}
+	pool := fakeRealtime(t, srv.serve).NewSessionPool(2)
+	defer pool.Close()
+
+	ctx := context.Background()
+	opts := TTSOptions{Model: ModelFlashRealtime, Voice: "Cherry"}
+	var sessions []*Session
+	for range 3 {
+		s, reused, err := pool.get(ctx, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reused {
+			t.Fatal("got a pooled session while all were in use")
+		}
+		sessions = append(sessions, s)
+	}
+	for _, s := range sessions {
+		pool.put(s)
+	}
+	if got := len(pool.idle[opts.Model+"\x00"+opts.Voice]); got != 2 {
+		t.Errorf("pool keeps %d idle sessions, want at most 2", got)
+	}
+	if !sessions[2].broken {
+		t.Error("session over the idle limit wasn't closed")
+	}
+}
+
+func TestSessionPoolRedialsDeadSession(t *testing.T) {
+	srv := &ttsServer{hangUpAfter: true}
+	pool := fakeRealtime(t, srv.serve).NewSessionPool(2)
+	defer pool.Close()
+
+	opts := TTSOptions{Model: ModelFlashRealtime, Voice: "Cherry", Text: "one"}
+	synthesize(t, pool, opts)
+	// The pooled session was hung up on; the pool must notice and redial
+	// without failing or retrying
+	synthesize(t, pool, opts)
+	if got := srv.dials.Load(); got != 2 {
+		t.Errorf("dialed %d times, want 2", got)
+	}
+}
+
+func TestSessionPoolClose(t *testing.T) {
+	srv := &ttsServer{}
+	pool := fakeRealtime(t, srv.serve).NewSessionPool(2)
+
+	opts := TTSOptions{Model: ModelFlashRealtime, Voice: "Cherry", Text: "one"}
+	synthesize(t, pool, opts)
+	pool.Close()
+	if len(pool.idle) != 0 {
+		t.Errorf("%d voices still have idle sessions after Close", len(pool.idle))
+	}
+	// A session returned after Close is closed rather than kept
+	s, _, err := pool.get(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool.put(s)
+	if !s.broken || len(pool.idle) != 0 {
+		t.Error("session returned after Close was kept")
+	}
+}
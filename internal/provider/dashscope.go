@@ -0,0 +1,214 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/ontypehq/vox/internal/audio/wav"
+	"github.com/ontypehq/vox/internal/config"
+	"github.com/ontypehq/vox/internal/dashscope"
+)
+
+func init() {
+	Register("dashscope", newDashScope)
+}
+
+// dashScope adapts the DashScope HTTP and realtime clients to Provider
+type dashScope struct {
+	cfg      *config.AppConfig
+	client   *dashscope.Client
+	realtime *dashscope.RealtimeClient
+	sessions *dashscope.SessionPool
+}
+
+func newDashScope(cfg *config.AppConfig) (Provider, error) {
+	ds := cfg.DashScope()
+	ep, err := dashscope.ResolveEndpoints(ds.Region, ds.BaseURL, ds.HTTPURL, ds.WSURL)
+	if err != nil {
+		return nil, err
+	}
+	retry := dashscope.DefaultRetry
+	if ds.Retry.MaxAttempts > 0 {
+		retry.MaxAttempts = ds.Retry.MaxAttempts
+	}
+	if ds.Retry.BaseDelayMS > 0 {
+		retry.BaseDelay = time.Duration(ds.Retry.BaseDelayMS) * time.Millisecond
+	}
+	if ds.Retry.MaxDelayMS > 0 {
+		retry.MaxDelay = time.Duration(ds.Retry.MaxDelayMS) * time.Millisecond
+	}
+
+	client := dashscope.NewClient(ds.APIKey, ep)
+	client.Retry = retry
+	realtime := dashscope.NewRealtimeClient(ds.APIKey, ep)
+	realtime.Retry = retry
+	return &dashScope{
+		cfg:      cfg,
+		client:   client,
+		realtime: realtime,
+		sessions: realtime.NewSessionPool(4),
+	}, nil
+}
+
+func (d *dashScope) Name() string { return "dashscope" }
+
+func (d *dashScope) Ready() error {
+	_, err := d.cfg.RequireAPIKey()
+	return err
+}
+
+func (d *dashScope) Close() error {
+	return d.sessions.Close()
+}
+
+func (d *dashScope) TTSModel(voice string, instruct bool) string {
+	if instruct && dashscope.IsSystemVoice(voice) {
+		return dashscope.ModelInstructRealtime
+	}
+	return dashscope.ModelForVoice(voice)
+}
+
+func (d *dashScope) StreamTTS(ctx context.Context, opts TTSOptions, onAudio func([]byte)) error {
+	return d.sessions.StreamTTS(ctx, ttsOptions(opts), onAudio)
+}
+
+func (d *dashScope) StreamTTSInput(ctx context.Context, opts TTSOptions, text <-chan string, onAudio func([]byte)) error {
+	return d.realtime.StreamTTSInput(ctx, ttsOptions(opts), text, onAudio)
+}
+
+func ttsOptions(opts TTSOptions) dashscope.TTSOptions {
+	return dashscope.TTSOptions{
+		Model:      opts.Model,
+		Voice:      opts.Voice,
+		Text:       opts.Text,
+		Lang:       opts.Lang,
+		Instruct:   opts.Instruct,
+		SpeechRate: opts.SpeechRate,
+		Volume:     opts.Volume,
+		PitchRate:  opts.PitchRate,
+	}
+}
+
+func (d *dashScope) ASRModel() string { return dashscope.ModelASRFlash }
+
+func (d *dashScope) Transcribe(wavData []byte, hint string) (*Transcript, error) {
+	result, err := d.client.Transcribe(wavData, hint)
+	if err != nil {
+		return nil, err
+	}
+	return &Transcript{Text: result.Text}, nil
+}
+
+// timedTurnSilence ends a turn at a pause short enough that each turn makes
+// a readable caption
+const timedTurnSilence = 400 * time.Millisecond
+
+// TranscribeTimed streams the file through a realtime session, whose
+// server-side turn detection reports where each turn was spoken
+func (d *dashScope) TranscribeTimed(wavData []byte, hint string) (*Transcript, error) {
+	a, err := wav.Decode(wavData)
+	if err != nil {
+		return nil, err
+	}
+	const rate = 16000
+	pcm := a.Convert(rate)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	audio := make(chan []byte)
+	go func() {
+		defer close(audio)
+		const chunk = rate * 2 / 10 // 100ms
+		for len(pcm) > 0 {
+			n := min(chunk, len(pcm))
+			select {
+			case audio <- pcm[:n]:
+			case <-ctx.Done():
+				return
+			}
+			pcm = pcm[n:]
+		}
+	}()
+
+	opts := dashscope.ASRStreamOptions{Model: dashscope.ModelASRRealtime, Context: hint, SampleRate: rate, TurnSilence: timedTurnSilence}
+	result, err := d.realtime.StreamASR(ctx, opts, audio, nil)
+	if err != nil {
+		return nil, err
+	}
+	t := &Transcript{Text: result.Text}
+	for _, seg := range result.Segments {
+		t.Segments = append(t.Segments, TranscriptSegment{Start: seg.Start, End: seg.End, Text: seg.Text})
+	}
+	return t, nil
+}
+
+func (d *dashScope) StreamTranscribe(ctx context.Context, audio <-chan []byte, hint string, onPartial func(string)) (*Transcript, error) {
+	opts := dashscope.ASRStreamOptions{Model: dashscope.ModelASRRealtime, Context: hint, SampleRate: 16000}
+	result, err := d.realtime.StreamASR(ctx, opts, audio, onPartial)
+	if err != nil {
+		return nil, err
+	}
+	return &Transcript{Text: result.Text}, nil
+}
+
+func (d *dashScope) SystemVoices() []SystemVoice {
+	voices := make([]SystemVoice, len(dashscope.SystemVoices))
+	for i, v := range dashscope.SystemVoices {
+		voices[i] = SystemVoice{ID: v.ID, Name: v.Name, Language: v.Language, Gender: v.Gender}
+	}
+	return voices
+}
+
+func (d *dashScope) EnrollVoice(name string, wavData []byte) (*Voice, error) {
+	result, err := d.client.EnrollVoice(name, base64.StdEncoding.EncodeToString(wavData))
+	if err != nil {
+		return nil, err
+	}
+	return &Voice{ID: result.VoiceID, Name: name, Model: result.TargetModel}, nil
+}
+
+func (d *dashScope) ListVoices() ([]Voice, error) {
+	var voices []Voice
+	for v, err := range d.client.AllVoices(50) {
+		if err != nil {
+			return nil, err
+		}
+		name := v.PreferredName
+		if name == "" {
+			name = nameFromVoiceID(v.ID)
+		}
+		voices = append(voices, Voice{
+			ID:        v.ID,
+			Name:      name,
+			Language:  v.Language,
+			Model:     v.TargetModel,
+			CreatedAt: v.CreatedAt,
+			UpdatedAt: v.UpdatedAt,
+		})
+	}
+	return voices, nil
+}
+
+func (d *dashScope) DeleteVoice(voiceID string) error {
+	_, err := d.client.DeleteVoice(voiceID)
+	return err
+}
+
+// nameFromVoiceID extracts the user-chosen name from voice ID
+// e.g. "qwen-tts-vc-dio-voice-20260220..." → "dio"
+func nameFromVoiceID(id string) string {
+	// Pattern: qwen-tts-vc-<name>-voice-<timestamp>-<hash>
+	const prefix = "qwen-tts-vc-"
+	const marker = "-voice-"
+	if !strings.HasPrefix(id, prefix) {
+		return id
+	}
+	rest := id[len(prefix):]
+	idx := strings.Index(rest, marker)
+	if idx < 0 {
+		return id
+	}
+	return rest[:idx]
+}
@@ -0,0 +1,182 @@
+// Package provider defines the speech engine interfaces used by the CLI.
+// Each engine registers a factory under a name; DashScope is the default.
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ontypehq/vox/internal/config"
+)
+
+// DefaultName is the provider used when neither config nor --provider picks one
+const DefaultName = "dashscope"
+
+// ErrUnsupported is returned by engines that don't implement a capability
+var ErrUnsupported = errors.New("not supported by this provider")
+
+// TTSOptions holds all parameters for a TTS request
+type TTSOptions struct {
+	Model      string
+	Voice      string
+	Text       string
+	Lang       string
+	Instruct   string
+	SpeechRate float64
+	Volume     int     // 1-100, 0 = default (50)
+	PitchRate  float64 // 0.5-2.0, 0 = default (1.0)
+}
+
+// Transcript holds the transcription output
+type Transcript struct {
+	Text     string
+	Segments []TranscriptSegment // timed pieces, when known
+}
+
+// TranscriptSegment is text recognized in one span of the input audio
+type TranscriptSegment struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+// SystemVoice is a preset voice shipped by the engine
+type SystemVoice struct {
+	ID       string
+	Name     string
+	Language string
+	Gender   string
+}
+
+// Voice is a user-enrolled (cloned) voice
+type Voice struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Language  string    `json:"language,omitempty"`
+	Model     string    `json:"model,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitzero"`
+	UpdatedAt time.Time `json:"updated_at,omitzero"`
+}
+
+// Synthesizer streams TTS audio as 24kHz 16-bit mono PCM chunks
+type Synthesizer interface {
+	// TTSModel picks the model for a voice. instruct asks for a model that
+	// accepts style instructions.
+	TTSModel(voice string, instruct bool) string
+	StreamTTS(ctx context.Context, opts TTSOptions, onAudio func([]byte)) error
+}
+
+// IncrementalSynthesizer is implemented by engines that can start speaking
+// before the full text is known
+type IncrementalSynthesizer interface {
+	// StreamTTSInput synthesizes text as it arrives on the channel and
+	// returns once it is closed and all audio has been delivered.
+	// opts.Text is ignored.
+	StreamTTSInput(ctx context.Context, opts TTSOptions, text <-chan string, onAudio func([]byte)) error
+}
+
+// Transcriber converts recorded speech to text
+type Transcriber interface {
+	ASRModel() string
+	// Transcribe takes WAV file bytes. hint is optional text context.
+	Transcribe(wavData []byte, hint string) (*Transcript, error)
+}
+
+// TimedTranscriber is implemented by engines that can return when each
+// part of the transcript was spoken
+type TimedTranscriber interface {
+	// TranscribeTimed is Transcribe with Segments filled in, when the
+	// engine has timing for the audio
+	TranscribeTimed(wavData []byte, hint string) (*Transcript, error)
+}
+
+// StreamingTranscriber is implemented by engines with realtime ASR
+type StreamingTranscriber interface {
+	// StreamTranscribe consumes 16kHz 16-bit mono PCM chunks until audio is
+	// closed. onPartial receives the interim transcript as it evolves.
+	StreamTranscribe(ctx context.Context, audio <-chan []byte, hint string, onPartial func(string)) (*Transcript, error)
+}
+
+// VoiceEnroller manages cloned voices
+type VoiceEnroller interface {
+	SystemVoices() []SystemVoice
+	// EnrollVoice creates a voice from WAV file bytes
+	EnrollVoice(name string, wavData []byte) (*Voice, error)
+	// ListVoices returns every enrolled voice, across all pages
+	ListVoices() ([]Voice, error)
+	DeleteVoice(voiceID string) error
+}
+
+// Provider is a speech engine. Engines that lack a capability return
+// ErrUnsupported from the corresponding methods.
+type Provider interface {
+	Name() string
+	// Ready reports missing credentials or settings
+	Ready() error
+	// Close releases pooled connections
+	Close() error
+	Synthesizer
+	Transcriber
+	VoiceEnroller
+}
+
+// Factory builds a provider from the loaded config
+type Factory func(cfg *config.AppConfig) (Provider, error)
+
+var factories = map[string]Factory{}
+
+// Register makes a provider available under name. Called from init.
+func Register(name string, f Factory) {
+	factories[name] = f
+}
+
+// Names returns the registered provider names, sorted
+func Names() []string {
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Open builds the provider selected by --provider, config, or the default,
+// and checks that it is ready to make API calls
+func Open(cfg *config.AppConfig) (Provider, error) {
+	p, err := Lookup(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.Ready(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Lookup builds the selected provider without checking credentials, for
+// offline operations such as listing preset voices
+func Lookup(cfg *config.AppConfig) (Provider, error) {
+	name := cfg.ProviderName()
+	if name == "" {
+		name = DefaultName
+	}
+	f, ok := factories[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	return f(cfg)
+}
+
+// IsSystemVoice checks if the given voice ID is one of the provider's presets
+func IsSystemVoice(p VoiceEnroller, voiceID string) bool {
+	for _, v := range p.SystemVoices() {
+		if v.ID == voiceID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,244 @@
+// Package segment splits text into sentence- and clause-sized pieces for
+// synthesis. It understands Latin and CJK punctuation.
+package segment
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultMaxLen is a segment size (in runes) that synthesizes in a few
+// seconds and keeps time-to-first-audio low
+const DefaultMaxLen = 200
+
+// sentence terminators that end a sentence wherever they appear
+func isCJKTerminator(r rune) bool {
+	switch r {
+	case '。', '！', '？', '；', '…', '｡':
+		return true
+	}
+	return false
+}
+
+// sentence terminators that need trailing whitespace (or end of text)
+func isLatinTerminator(r rune) bool {
+	switch r {
+	case '.', '!', '?', ';':
+		return true
+	}
+	return false
+}
+
+// isCloser reports quotes and brackets that belong to the preceding sentence
+func isCloser(r rune) bool {
+	switch r {
+	case '"', '\'', ')', ']', '”', '’', '）', '」', '』', '】', '》':
+		return true
+	}
+	return false
+}
+
+func isClauseBreak(r rune) bool {
+	switch r {
+	case ',', ':', '，', '、', '：', '—', '–':
+		return true
+	}
+	return false
+}
+
+// abbreviations that end in a period but rarely end a sentence
+var abbreviations = map[string]bool{
+	"mr": true, "mrs": true, "ms": true, "dr": true, "prof": true, "sr": true, "jr": true,
+	"st": true, "vs": true, "etc": true, "e.g": true, "i.e": true, "fig": true,
+}
+
+// numberAbbreviations are only abbreviations in front of a number ("No. 5"),
+// and otherwise ordinary words that may end a sentence
+var numberAbbreviations = map[string]bool{
+	"no": true, "nos": true, "vol": true, "p": true, "pp": true,
+}
+
+// ends returns the byte offsets just past every sentence end in s. When
+// final is false, s may still grow, so a Latin terminator at the very end
+// doesn't count yet.
+func ends(s string, final bool) []int {
+	var out []int
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		next := i + size
+		switch {
+		case r == '\n':
+			out = append(out, next)
+		case isCJKTerminator(r):
+			for next < len(s) {
+				r2, sz := utf8.DecodeRuneInString(s[next:])
+				if !isCJKTerminator(r2) && !isCloser(r2) {
+					break
+				}
+				next += sz
+			}
+			out = append(out, next)
+		case isLatinTerminator(r):
+			// Absorb runs like "?!" or "..." and closing quotes
+			for next < len(s) {
+				r2, sz := utf8.DecodeRuneInString(s[next:])
+				if !isLatinTerminator(r2) && !isCloser(r2) {
+					break
+				}
+				next += sz
+			}
+			if next == len(s) {
+				if final {
+					out = append(out, next)
+				}
+			} else if r2, _ := utf8.DecodeRuneInString(s[next:]); unicode.IsSpace(r2) && !(r == '.' && isAbbreviation(s[:i], s[next:])) {
+				out = append(out, next)
+			}
+		}
+		i = next
+	}
+	return out
+}
+
+// isAbbreviation reports whether the word ending at the period is a known
+// abbreviation or a single initial ("J. Smith"). after is the text following
+// the period.
+func isAbbreviation(before, after string) bool {
+	start := strings.LastIndexFunc(before, unicode.IsSpace) + 1
+	word := strings.ToLower(before[start:])
+	if utf8.RuneCountInString(word) == 1 && unicode.IsUpper([]rune(before[start:])[0]) {
+		return true
+	}
+	if numberAbbreviations[word] {
+		next, _ := utf8.DecodeRuneInString(strings.TrimLeftFunc(after, unicode.IsSpace))
+		return unicode.IsDigit(next)
+	}
+	return abbreviations[word]
+}
+
+// LastSentenceEnd returns the byte offset just past the last complete
+// sentence in s, or 0 if there is none yet. s may still be growing (e.g.
+// streamed LLM output), so a trailing "." only counts once whitespace
+// follows it.
+func LastSentenceEnd(s string) int {
+	e := ends(s, false)
+	if len(e) == 0 {
+		return 0
+	}
+	return e[len(e)-1]
+}
+
+// Sentences splits complete text into trimmed, non-empty sentences
+func Sentences(text string) []string {
+	var out []string
+	prev := 0
+	for _, end := range ends(text, true) {
+		if s := strings.TrimSpace(text[prev:end]); s != "" {
+			out = append(out, s)
+		}
+		prev = end
+	}
+	if s := strings.TrimSpace(text[prev:]); s != "" {
+		out = append(out, s)
+	}
+	return out
+}
+
+// Split breaks text into segments of at most maxLen runes. Consecutive
+// sentences are packed together; a sentence that is too long is broken at
+// clause punctuation, then whitespace, then anywhere.
+func Split(text string, maxLen int) []string {
+	if maxLen <= 0 {
+		maxLen = DefaultMaxLen
+	}
+
+	var out []string
+	var cur strings.Builder
+	curLen := 0
+	flush := func() {
+		if cur.Len() > 0 {
+			out = append(out, cur.String())
+			cur.Reset()
+			curLen = 0
+		}
+	}
+
+	for _, sentence := range Sentences(text) {
+		for _, piece := range splitLong(sentence, maxLen) {
+			n := utf8.RuneCountInString(piece)
+			if curLen > 0 && curLen+1+n > maxLen {
+				flush()
+			}
+			if curLen > 0 && needsSpace(cur.String(), piece) {
+				cur.WriteByte(' ')
+				curLen++
+			}
+			cur.WriteString(piece)
+			curLen += n
+		}
+	}
+	flush()
+	return out
+}
+
+// Join concatenates pieces of text, with a space between Latin pieces and
+// none between CJK ones. Empty pieces are skipped.
+func Join(pieces []string) string {
+	var b strings.Builder
+	for _, p := range pieces {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if b.Len() > 0 && needsSpace(b.String(), p) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(p)
+	}
+	return b.String()
+}
+
+// splitLong breaks a single sentence that exceeds maxLen runes
+func splitLong(s string, maxLen int) []string {
+	var out []string
+	for utf8.RuneCountInString(s) > maxLen {
+		runes := []rune(s)
+		window := runes[:maxLen]
+
+		cut := -1
+		for i := len(window) - 1; i > maxLen/3 && cut < 0; i-- {
+			if isClauseBreak(window[i]) {
+				cut = i + 1
+			}
+		}
+		for i := len(window) - 1; i > maxLen/3 && cut < 0; i-- {
+			if unicode.IsSpace(window[i]) {
+				cut = i
+			}
+		}
+		if cut < 0 {
+			cut = maxLen
+		}
+
+		out = append(out, strings.TrimSpace(string(runes[:cut])))
+		s = strings.TrimSpace(string(runes[cut:]))
+	}
+	if s != "" {
+		out = append(out, s)
+	}
+	return out
+}
+
+// needsSpace reports whether joining a and b needs a separating space.
+// CJK text is written without spaces between sentences.
+func needsSpace(a, b string) bool {
+	last, _ := utf8.DecodeLastRuneInString(a)
+	first, _ := utf8.DecodeRuneInString(b)
+	return !isWide(last) && !isWide(first)
+}
+
+func isWide(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) ||
+		isCJKTerminator(r) || (r >= 0xFF00 && r <= 0xFFEF) || (r >= 0x3000 && r <= 0x303F)
+}
@@ -0,0 +1,105 @@
+package segment
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		maxLen int
+		want   []string
+	}{
+		{"empty", "", 10, nil},
+		{"packs sentences", "Hello world. How are you? Fine!", 200, []string{"Hello world. How are you? Fine!"}},
+		{"one sentence each", "Hello world. How are you? Fine!", 15, []string{"Hello world.", "How are you?", "Fine!"}},
+		{"abbreviation", "Dr. Smith is here. He left.", 20, []string{"Dr. Smith is here.", "He left."}},
+		{"no ends a sentence", "The answer is no. We leave.", 20, []string{"The answer is no.", "We leave."}},
+		{"no before a number", "See No. 5 below. We leave.", 20, []string{"See No. 5 below.", "We leave."}},
+		{"initial", "J. Smith wrote it. Then he left.", 20, []string{"J. Smith wrote it.", "Then he left."}},
+		{"decimal", "It costs 3.14 dollars. OK.", 24, []string{"It costs 3.14 dollars.", "OK."}},
+		{"closing quote", "He said \"stop.\" Then he left.", 16, []string{"He said \"stop.\"", "Then he left."}},
+		{"newline", "line one\nline two", 8, []string{"line one", "line two"}},
+		{"cjk", "今天天气很好。我们去公园吧！好的", 10, []string{"今天天气很好。", "我们去公园吧！好的"}},
+		{"cjk packed", "今天天气很好。我们去公园吧！好的", 200, []string{"今天天气很好。我们去公园吧！好的"}},
+		{"cjk clauses", "第一部分很长很长，第二部分也很长很长，第三部分", 12, []string{"第一部分很长很长，", "第二部分也很长很长，", "第三部分"}},
+		{"cjk then latin", "你好。世界。Hello there.", 200, []string{"你好。世界。Hello there."}},
+		{"words", "one two three four five six", 14, []string{"one two three", "four five six"}},
+		{"no break", "abcdefghijklmnopqrstuvwxyz", 10, []string{"abcdefghij", "klmnopqrst", "uvwxyz"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Split(tt.text, tt.maxLen); !slices.Equal(got, tt.want) {
+				t.Errorf("Split(%q, %d) = %q, want %q", tt.text, tt.maxLen, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitLimit(t *testing.T) {
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog, again and again; ", 20) +
+		strings.Repeat("技术正在以前所未有的速度发展，改变着我们的生活方式。", 20)
+	for _, maxLen := range []int{5, 17, 50, DefaultMaxLen} {
+		segments := Split(text, maxLen)
+		for _, s := range segments {
+			if n := utf8.RuneCountInString(s); n > maxLen {
+				t.Errorf("maxLen %d: segment of %d runes: %q", maxLen, n, s)
+			}
+		}
+		// Nothing is lost but whitespace
+		squash := func(s string) string { return strings.Join(strings.Fields(s), "") }
+		if got := squash(strings.Join(segments, "")); got != squash(text) {
+			t.Errorf("maxLen %d: segments don't add back up to the text", maxLen)
+		}
+	}
+}
+
+func TestSentences(t *testing.T) {
+	got := Sentences("  First one.  Second?! \"Third.\" 第四。第五！ trailing")
+	want := []string{"First one.", "Second?!", "\"Third.\"", "第四。", "第五！", "trailing"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Sentences = %q, want %q", got, want)
+	}
+}
+
+func TestLastSentenceEnd(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"Hello", 0},
+		{"Hello.", 0}, // may still become "Hello.com"
+		{"Hello. Wor", 6},
+		{"Mr. Smith", 0},
+		{"One. Two! Thr", 9},
+		{"你好。世", len("你好。")},
+		{"line\nmore", 5},
+	}
+	for _, tt := range tests {
+		if got := LastSentenceEnd(tt.text); got != tt.want {
+			t.Errorf("LastSentenceEnd(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestJoin(t *testing.T) {
+	tests := []struct {
+		pieces []string
+		want   string
+	}{
+		{nil, ""},
+		{[]string{"Hello.", " World. ", ""}, "Hello. World."},
+		{[]string{"你好。", "世界。"}, "你好。世界。"},
+		{[]string{"你好。", "OK."}, "你好。OK."},
+	}
+	for _, tt := range tests {
+		if got := Join(tt.pieces); got != tt.want {
+			t.Errorf("Join(%q) = %q, want %q", tt.pieces, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// Package subtitle writes timed text as SubRip (SRT) or WebVTT
+package subtitle
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Cue is one caption shown from Start to End
+type Cue struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+// WriteSRT writes cues in SubRip format
+func WriteSRT(w io.Writer, cues []Cue) error {
+	bw := bufio.NewWriter(w)
+	for i, c := range cues {
+		fmt.Fprintf(bw, "%d\n%s --> %s\n%s\n\n", i+1, timestamp(c.Start, ','), timestamp(c.End, ','), clean(c.Text))
+	}
+	return bw.Flush()
+}
+
+// WriteVTT writes cues in WebVTT format
+func WriteVTT(w io.Writer, cues []Cue) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("WEBVTT\n\n")
+	for _, c := range cues {
+		fmt.Fprintf(bw, "%s --> %s\n%s\n\n", timestamp(c.Start, '.'), timestamp(c.End, '.'), clean(c.Text))
+	}
+	return bw.Flush()
+}
+
+// timestamp formats d as hh:mm:ss followed by sep and milliseconds
+func timestamp(d time.Duration, sep byte) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d%c%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
+
+// clean removes blank lines, which would end a cue early, and "-->",
+// which would be read as a timing line
+func clean(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, strings.ReplaceAll(line, "-->", "->"))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,46 @@
+package subtitle
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+var cues = []Cue{
+	{Start: 1500 * time.Millisecond, End: 3 * time.Second, Text: "Hello there."},
+	{Start: time.Hour + 2*time.Minute + 3*time.Second + 45*time.Millisecond, End: time.Hour + 2*time.Minute + 5*time.Second, Text: "Two\n\n  lines --> here  "},
+}
+
+func TestWriteSRT(t *testing.T) {
+	var b strings.Builder
+	if err := WriteSRT(&b, cues); err != nil {
+		t.Fatal(err)
+	}
+	want := "1\n00:00:01,500 --> 00:00:03,000\nHello there.\n\n" +
+		"2\n01:02:03,045 --> 01:02:05,000\nTwo\nlines -> here\n\n"
+	if b.String() != want {
+		t.Errorf("got\n%q\nwant\n%q", b.String(), want)
+	}
+}
+
+func TestWriteVTT(t *testing.T) {
+	var b strings.Builder
+	if err := WriteVTT(&b, cues); err != nil {
+		t.Fatal(err)
+	}
+	want := "WEBVTT\n\n" +
+		"00:00:01.500 --> 00:00:03.000\nHello there.\n\n" +
+		"01:02:03.045 --> 01:02:05.000\nTwo\nlines -> here\n\n"
+	if b.String() != want {
+		t.Errorf("got\n%q\nwant\n%q", b.String(), want)
+	}
+}
+
+func TestWriteEmpty(t *testing.T) {
+	var srt, vtt strings.Builder
+	WriteSRT(&srt, nil)
+	WriteVTT(&vtt, nil)
+	if srt.String() != "" || vtt.String() != "WEBVTT\n\n" {
+		t.Errorf("empty output: SRT %q, VTT %q", srt.String(), vtt.String())
+	}
+}
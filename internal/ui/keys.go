@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync/atomic"
+
+	"github.com/charmbracelet/x/term"
+)
+
+// KeyInterrupt is Ctrl+C as ReadKeys delivers it: raw mode turns it into a
+// byte instead of a signal
+const KeyInterrupt = 0x03
+
+// raw is set while ReadKeys has the terminal in raw mode
+var raw atomic.Bool
+
+// statusShown is set while a Status line is on screen
+var statusShown atomic.Bool
+
+// stderr writes to os.Stderr. It erases a Status line before other output,
+// so a message never lands at the end of one, and turns \n into \r\n while
+// the terminal is in raw mode (which stops the tty from doing it).
+var stderr crlfWriter
+
+type crlfWriter struct{}
+
+// Writer returns the stderr writer ui uses, for output from other packages
+// (such as loggers) that must not break the terminal while it is in raw
+// mode
+func Writer() io.Writer {
+	return stderr
+}
+
+func (crlfWriter) Write(p []byte) (int, error) {
+	out := p
+	if statusShown.Swap(false) && !bytes.HasPrefix(p, []byte("\r")) {
+		out = append([]byte("\r\033[K"), p...)
+	}
+	if raw.Load() {
+		out = bytes.ReplaceAll(out, []byte("\n"), []byte("\r\n"))
+	}
+	if _, err := os.Stderr.Write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// ReadKeys puts the terminal in raw mode and sends each key pressed until
+// the returned function is called, which restores the terminal. If stdin or
+// stderr isn't a terminal the channel is nil, so callers keep relying on
+// signals.
+func ReadKeys() (<-chan byte, func()) {
+	fd := os.Stdin.Fd()
+	if !term.IsTerminal(fd) || !IsTerminal() {
+		return nil, func() {}
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, func() {}
+	}
+	raw.Store(true)
+
+	keys := make(chan byte, 16)
+	go func() {
+		buf := make([]byte, 16)
+		for {
+			n, err := os.Stdin.Read(buf)
+			if err != nil {
+				return
+			}
+			for _, b := range buf[:n] {
+				select {
+				case keys <- b:
+				default: // nobody listening; drop the key
+				}
+			}
+		}
+	}()
+
+	return keys, func() {
+		if raw.Swap(false) {
+			term.Restore(fd, state)
+		}
+	}
+}
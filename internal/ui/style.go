@@ -5,6 +5,8 @@ import (
 	"os"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/term"
+	"github.com/mattn/go-runewidth"
 )
 
 var (
@@ -23,21 +25,64 @@ func Key(s string) string    { return key.Render(s) }
 func Val(s string) string    { return val.Render(s) }
 
 func Success(format string, a ...any) {
-	fmt.Fprintln(os.Stderr, success.Render("✓ "+fmt.Sprintf(format, a...)))
+	fmt.Fprintln(stderr, success.Render("✓ "+fmt.Sprintf(format, a...)))
 }
 
 func Warn(format string, a ...any) {
-	fmt.Fprintln(os.Stderr, warn.Render("! "+fmt.Sprintf(format, a...)))
+	fmt.Fprintln(stderr, warn.Render("! "+fmt.Sprintf(format, a...)))
 }
 
 func Error(format string, a ...any) {
-	fmt.Fprintln(os.Stderr, errStyle.Render("✗ "+fmt.Sprintf(format, a...)))
+	fmt.Fprintln(stderr, errStyle.Render("✗ "+fmt.Sprintf(format, a...)))
 }
 
 func Info(format string, a ...any) {
-	fmt.Fprintln(os.Stderr, fmt.Sprintf(format, a...))
+	fmt.Fprintln(stderr, fmt.Sprintf(format, a...))
 }
 
 func KV(k, v string) {
-	fmt.Fprintf(os.Stderr, "  %s  %s\n", key.Render(k), val.Render(v))
+	fmt.Fprintf(stderr, "  %s  %s\n", key.Render(k), val.Render(v))
+}
+
+// IsTerminal reports whether stderr is an interactive terminal
+func IsTerminal() bool {
+	return term.IsTerminal(os.Stderr.Fd())
+}
+
+// Status overwrites the current stderr line with a dimmed transient message,
+// keeping the tail if it is wider than the terminal. No-op when stderr isn't
+// a terminal.
+func Status(format string, a ...any) {
+	if !IsTerminal() {
+		return
+	}
+	line := fmt.Sprintf(format, a...)
+	if w, _, err := term.GetSize(os.Stderr.Fd()); err == nil && w > 1 {
+		line = tail(line, w-1)
+	}
+	fmt.Fprint(stderr, "\r\033[K"+dim.Render(line))
+	statusShown.Store(true)
+}
+
+// ClearStatus erases the line written by Status
+func ClearStatus() {
+	if IsTerminal() {
+		fmt.Fprint(stderr, "\r\033[K")
+		statusShown.Store(false)
+	}
+}
+
+// tail returns the rightmost part of s that fits in width cells
+func tail(s string, width int) string {
+	if runewidth.StringWidth(s) <= width {
+		return s
+	}
+	r := []rune(s)
+	w := 0
+	i := len(r)
+	for i > 0 && w+runewidth.RuneWidth(r[i-1]) <= width-1 {
+		i--
+		w += runewidth.RuneWidth(r[i])
+	}
+	return "…" + string(r[i:])
 }
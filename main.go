@@ -10,12 +10,23 @@ import (
 )
 
 var cli struct {
-	Auth   cmd.AuthCmd   `cmd:"" help:"Manage authentication"`
-	Say    cmd.SayCmd    `cmd:"" help:"Speak text with TTS"`
-	Hear   cmd.HearCmd   `cmd:"" help:"Transcribe speech to text"`
-	Listen cmd.ListenCmd `cmd:"" help:"Listen to Slack and speak messages aloud"`
-	Voice  cmd.VoiceCmd  `cmd:"" help:"Manage voice profiles"`
-	Cache  cmd.CacheCmd  `cmd:"" help:"Manage audio cache"`
+	Provider string `help:"Speech provider (default: dashscope)" env:"VOX_PROVIDER"`
+	Region   string `help:"DashScope region (cn, intl)" env:"VOX_DASHSCOPE_REGION"`
+	BaseURL  string `name:"base-url" help:"DashScope API host, overrides region (e.g. http://localhost:8080)" env:"VOX_DASHSCOPE_BASE_URL"`
+
+	InputDevice  string  `name:"input-device" help:"Microphone to record from: name, number or ID from vox devices" env:"VOX_INPUT_DEVICE"`
+	OutputDevice string  `name:"output-device" help:"Device to play audio on: name, number or ID from vox devices" env:"VOX_OUTPUT_DEVICE"`
+	Sink         string  `help:"Where audio plays: auto, device, null, file:PATH or cmd:COMMAND (default: auto)" env:"VOX_SINK"`
+	Loudness     float64 `placeholder:"LUFS" help:"Normalize synthesized speech to this loudness, e.g. --loudness=-16 (default: off)" env:"VOX_LOUDNESS"`
+
+	Auth    cmd.AuthCmd    `cmd:"" help:"Manage authentication"`
+	Say     cmd.SayCmd     `cmd:"" help:"Speak text with TTS"`
+	Hear    cmd.HearCmd    `cmd:"" help:"Transcribe speech to text"`
+	Listen  cmd.ListenCmd  `cmd:"" help:"Listen to Slack and speak messages aloud"`
+	Voice   cmd.VoiceCmd   `cmd:"" help:"Manage voice profiles"`
+	Cache   cmd.CacheCmd   `cmd:"" help:"Manage audio cache"`
+	Formats cmd.FormatsCmd `cmd:"" help:"List audio output formats"`
+	Devices cmd.DevicesCmd `cmd:"" help:"List and choose audio devices"`
 }
 
 func main() {
@@ -30,6 +41,13 @@ func main() {
 		ui.Error("Failed to load config: %v", err)
 		os.Exit(1)
 	}
+	cfg.Overrides.Provider = cli.Provider
+	cfg.Overrides.Region = cli.Region
+	cfg.Overrides.BaseURL = cli.BaseURL
+	cfg.Overrides.InputDevice = cli.InputDevice
+	cfg.Overrides.OutputDevice = cli.OutputDevice
+	cfg.Overrides.Sink = cli.Sink
+	cfg.Overrides.Loudness = cli.Loudness
 
 	err = ctx.Run(cfg)
 	ctx.FatalIfErrorf(err)